@@ -0,0 +1,270 @@
+package xparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var ErrParsePanic = errors.New("parser panicked")
+
+// Document is a single input of ParseBatch
+type Document struct {
+	// ID is an optional identifier of the document, it is copied to Result.ID as-is
+	ID string
+	// Raw is the raw html/json content
+	Raw []byte
+	// Preset is bound to the parser by BindPresetData before parsing
+	Preset map[string]any
+}
+
+// Result is the output of one Document
+type Result struct {
+	// Index is the position of the document in the input channel
+	Index int
+	ID    string
+
+	Data map[string]any
+	Err  error
+
+	Started time.Time
+	Elapsed time.Duration
+}
+
+// BatchPlan describes how every document in a batch is parsed
+type BatchPlan struct {
+	// NewParser creates a new parser for each document,
+	// the parser holds per-document state, so it must never be shared between documents
+	NewParser func(raw []byte) IParser
+
+	// Ordered emits results in the same order as documents are received,
+	// otherwise results are emitted as soon as they are parsed
+	Ordered bool
+
+	// UpdateRefiners calls UpdateRefiners on each parser before DoParse
+	UpdateRefiners bool
+}
+
+// NewHTMLPlan creates a BatchPlan which parses every document with NewHTMLParser and the same yaml config
+func NewHTMLPlan(ymlMap ...[]byte) *BatchPlan {
+	return &BatchPlan{
+		NewParser: func(raw []byte) IParser {
+			return NewHTMLParser(raw, ymlMap...)
+		},
+	}
+}
+
+// NewJSONPlan creates a BatchPlan which parses every document with NewJSONParser and the same yaml config
+func NewJSONPlan(ymlMap ...[]byte) *BatchPlan {
+	return &BatchPlan{
+		NewParser: func(raw []byte) IParser {
+			return NewJSONParser(raw, ymlMap...)
+		},
+	}
+}
+
+// ParseBatch fans documents out over workers goroutines, and emits one Result per document.
+//
+//   - workers <= 0 means runtime.NumCPU()
+//   - the returned channel is closed when inputs is closed and all documents are parsed, or when ctx is done
+//   - panics raised by parsers are recovered and returned as Result.Err (wrapping ErrParsePanic)
+func ParseBatch(ctx context.Context, plan *BatchPlan, inputs <-chan Document, workers int) <-chan Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type job struct {
+		index int
+		doc   Document
+	}
+
+	jobs := make(chan job)
+	parsed := make(chan Result, workers)
+	out := make(chan Result, workers)
+
+	// dispatch: number documents by their arrival order
+	go func() {
+		defer close(jobs)
+
+		index := 0
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case doc, ok := <-inputs:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case jobs <- job{index: index, doc: doc}:
+					index++
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for jb := range jobs {
+				res := parseDocument(plan, jb.index, jb.doc)
+
+				select {
+				case <-ctx.Done():
+					return
+				case parsed <- res:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(parsed)
+	}()
+
+	go func() {
+		defer close(out)
+
+		if plan.Ordered {
+			emitInOrder(ctx, parsed, out)
+		} else {
+			emitAsParsed(ctx, parsed, out)
+		}
+	}()
+
+	return out
+}
+
+func emitAsParsed(ctx context.Context, parsed <-chan Result, out chan<- Result) {
+	for res := range parsed {
+		select {
+		case <-ctx.Done():
+			return
+		case out <- res:
+		}
+	}
+}
+
+func emitInOrder(ctx context.Context, parsed <-chan Result, out chan<- Result) {
+	pending := make(map[int]Result)
+	next := 0
+
+	for res := range parsed {
+		pending[res.Index] = res
+
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- r:
+			}
+
+			delete(pending, next)
+			next++
+		}
+	}
+}
+
+func parseDocument(plan *BatchPlan, index int, doc Document) Result {
+	res, _ := parseDocumentWith(plan, index, doc)
+	return res
+}
+
+// parseDocumentWith is parseDocument, and returns the parser too, parser is nil if it's not created
+func parseDocumentWith(plan *BatchPlan, index int, doc Document) (res Result, parser IParser) {
+	res = Result{Index: index, ID: doc.ID, Started: time.Now()}
+
+	defer func() {
+		if r := recover(); r != nil {
+			res.Err = fmt.Errorf("%w: %v", ErrParsePanic, r)
+		}
+
+		res.Elapsed = time.Since(res.Started)
+	}()
+
+	parser = plan.NewParser(doc.Raw)
+	parser.BindPresetData(doc.Preset)
+
+	if plan.UpdateRefiners {
+		UpdateRefiners(parser)
+	}
+
+	parser.DoParse()
+
+	res.Data, _ = parser.GetParsedData().(map[string]any)
+
+	return res, parser
+}
+
+// BatchStats is the aggregated stats of a batch
+type BatchStats struct {
+	Total  int
+	Failed int
+
+	// Errors is a map of Result.Index and its error
+	Errors map[int]error
+
+	// Elapsed is the sum of all documents' parsing time
+	Elapsed time.Duration
+	Min     time.Duration
+	Max     time.Duration
+}
+
+// Avg returns the average parsing time of each document
+func (s *BatchStats) Avg() time.Duration {
+	if s.Total == 0 {
+		return 0
+	}
+
+	return s.Elapsed / time.Duration(s.Total)
+}
+
+func (s *BatchStats) add(res Result) {
+	s.Total++
+	s.Elapsed += res.Elapsed
+
+	if s.Total == 1 || res.Elapsed < s.Min {
+		s.Min = res.Elapsed
+	}
+
+	if res.Elapsed > s.Max {
+		s.Max = res.Elapsed
+	}
+
+	if res.Err != nil {
+		s.Failed++
+		s.Errors[res.Index] = res.Err
+	}
+}
+
+// CollectBatch drains results returned by ParseBatch, and returns all results with the aggregated stats
+func CollectBatch(results <-chan Result) ([]Result, *BatchStats) {
+	var all []Result
+
+	stats := &BatchStats{Errors: make(map[int]error)}
+
+	for res := range results {
+		all = append(all, res)
+		stats.add(res)
+	}
+
+	return all, stats
+}
@@ -0,0 +1,76 @@
+package xparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _batchYaml = `
+page:
+  title: h1
+`
+
+func batchInputs(n int) <-chan Document {
+	inputs := make(chan Document)
+
+	go func() {
+		defer close(inputs)
+
+		for i := 0; i < n; i++ {
+			raw := fmt.Sprintf("<html><body><h1>title-%d</h1></body></html>", i)
+			inputs <- Document{ID: fmt.Sprintf("doc-%d", i), Raw: []byte(raw)}
+		}
+	}()
+
+	return inputs
+}
+
+func TestParseBatchOrdered(t *testing.T) {
+	assert := assert.New(t)
+
+	plan := NewHTMLPlan([]byte(_batchYaml))
+	plan.Ordered = true
+
+	results, stats := CollectBatch(ParseBatch(context.Background(), plan, batchInputs(20), 4))
+
+	assert.Len(results, 20)
+	assert.Equal(20, stats.Total)
+	assert.Equal(0, stats.Failed)
+	assert.LessOrEqual(stats.Min, stats.Max)
+
+	for i, res := range results {
+		assert.Equal(i, res.Index)
+		assert.Equal(fmt.Sprintf("doc-%d", i), res.ID)
+		assert.Equal(map[string]any{"title": fmt.Sprintf("title-%d", i)}, res.Data["page"])
+	}
+}
+
+func TestParseBatchErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	plan := NewHTMLPlan([]byte("page:\n  title:\n    _locator: h1\n    _index: [a]\n"))
+
+	results, stats := CollectBatch(ParseBatch(context.Background(), plan, batchInputs(3), 2))
+
+	assert.Len(results, 3)
+	assert.Equal(3, stats.Failed)
+	assert.Len(stats.Errors, 3)
+
+	for _, res := range results {
+		assert.True(errors.Is(res.Err, ErrParsePanic))
+	}
+}
+
+func TestParseBatchCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, _ := CollectBatch(ParseBatch(ctx, NewHTMLPlan([]byte(_batchYaml)), batchInputs(100), 2))
+	assert.Less(len(results), 100)
+}
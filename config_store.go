@@ -0,0 +1,195 @@
+package xparse
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/gookit/config/v2"
+	"github.com/spf13/cast"
+)
+
+const (
+	_defaultConfigPattern = "%v.yaml"
+)
+
+type ConfigStoreOpts struct {
+	pattern   string
+	hotReload bool
+}
+
+type ConfigStoreOptFunc func(o *ConfigStoreOpts)
+
+func bindConfigStoreOpts(opt *ConfigStoreOpts, opts ...ConfigStoreOptFunc) {
+	for _, f := range opts {
+		f(opt)
+	}
+}
+
+// WithConfigPattern sets how a site id is mapped to the file name by fmt.Sprintf, "%v.yaml" by default,
+// e.g. "sites/%v.yml"
+func WithConfigPattern(pattern string) ConfigStoreOptFunc {
+	return func(o *ConfigStoreOpts) {
+		o.pattern = pattern
+	}
+}
+
+// WithHotReload checks the modification time of a config and its parents every time it's loaded,
+// and reloads it if any of them is changed, it's for development only
+func WithHotReload(b bool) ConfigStoreOptFunc {
+	return func(o *ConfigStoreOpts) {
+		o.hotReload = b
+	}
+}
+
+// ConfigStore loads site configs from a fs.FS (os.DirFS, embed.FS, ...),
+// __raw.extends are resolved relative to the config file, and the compiled configs are cached
+//
+//	store := NewConfigStore(os.DirFS("configs"), WithConfigPattern("sites/%v.yaml"))
+//	p, err := store.NewHTMLParser(341, rawHTML)
+type ConfigStore struct {
+	fsys fs.FS
+	opt  *ConfigStoreOpts
+
+	mu    sync.RWMutex
+	cache map[string]*storedConfig
+}
+
+type storedConfig struct {
+	yaml   []byte
+	config *config.Config
+	// files are the config and its parents, with their modification time when loaded
+	files map[string]time.Time
+}
+
+func NewConfigStore(fsys fs.FS, opts ...ConfigStoreOptFunc) *ConfigStore {
+	opt := &ConfigStoreOpts{pattern: _defaultConfigPattern}
+	bindConfigStoreOpts(opt, opts...)
+
+	return &ConfigStore{
+		fsys:  fsys,
+		opt:   opt,
+		cache: make(map[string]*storedConfig),
+	}
+}
+
+// Yaml returns the resolved yaml of siteID
+func (s *ConfigStore) Yaml(siteID any) ([]byte, error) {
+	stored, err := s.load(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stored.yaml, nil
+}
+
+// NewHTMLParser creates a HTMLParser with the config of siteID, and PID is set to siteID
+func (s *ConfigStore) NewHTMLParser(siteID any, rawHTML []byte) (*HTMLParser, error) {
+	stored, err := s.load(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &HTMLParser{
+		Parser: NewParser(rawHTML, stored.yaml),
+	}
+	p.PID = cast.ToString(siteID)
+	p.bindConfig([][]byte{stored.yaml}, stored.yaml, stored.config)
+	p.LoadRootSelection(rawHTML)
+
+	return p, nil
+}
+
+// NewJSONParser creates a JSONParser with the config of siteID, and PID is set to siteID
+func (s *ConfigStore) NewJSONParser(siteID any, rawData []byte) (*JSONParser, error) {
+	stored, err := s.load(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &JSONParser{
+		NewParser(rawData, stored.yaml),
+	}
+	p.PID = cast.ToString(siteID)
+	p.bindConfig([][]byte{stored.yaml}, stored.yaml, stored.config)
+	p.LoadRootSelection(rawData)
+
+	return p, nil
+}
+
+// Invalidate removes the cached configs of siteIDs, or all configs if no siteID given
+func (s *ConfigStore) Invalidate(siteIDs ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(siteIDs) == 0 {
+		s.cache = make(map[string]*storedConfig)
+		return
+	}
+
+	for _, id := range siteIDs {
+		delete(s.cache, s.fileName(id))
+	}
+}
+
+func (s *ConfigStore) fileName(siteID any) string {
+	return fmt.Sprintf(s.opt.pattern, siteID)
+}
+
+func (s *ConfigStore) load(siteID any) (*storedConfig, error) {
+	name := s.fileName(siteID)
+
+	s.mu.RLock()
+	stored, ok := s.cache[name]
+	s.mu.RUnlock()
+
+	if ok && !(s.opt.hotReload && s.isChanged(stored)) {
+		return stored, nil
+	}
+
+	resolved, files, err := resolveYamlFile(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved == nil {
+		return nil, fmt.Errorf("empty config of %s", name)
+	}
+
+	stored = &storedConfig{
+		yaml:   resolved,
+		config: Yaml2Config(resolved),
+		files:  make(map[string]time.Time, len(files)),
+	}
+
+	for _, f := range files {
+		stored.files[f] = s.modTime(f)
+	}
+
+	s.mu.Lock()
+	s.cache[name] = stored
+	s.mu.Unlock()
+
+	return stored, nil
+}
+
+func (s *ConfigStore) isChanged(stored *storedConfig) bool {
+	for f, t := range stored.files {
+		if !s.modTime(f).Equal(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// modTime returns the modification time of name, zero if unknown (embed.FS)
+func (s *ConfigStore) modTime(name string) time.Time {
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
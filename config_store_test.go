@@ -0,0 +1,43 @@
+package xparse
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigStore(t *testing.T) {
+	assert := assert.New(t)
+
+	fsys := fstest.MapFS{
+		"base.yaml": {Data: []byte("jobs:\n  _locator: jobs\n  _index: ~\n  title: title\n")},
+		"sites/42.yaml": {
+			Data:    []byte("__raw:\n  extends: ../base.yaml\njobs:\n  id: id\n"),
+			ModTime: time.Unix(1, 0),
+		},
+	}
+
+	store := NewConfigStore(fsys, WithConfigPattern("sites/%v.yaml"), WithHotReload(true))
+
+	p, err := store.NewJSONParser(42, []byte(`{"jobs": [{"id": "a", "title": "A"}]}`))
+	assert.Nil(err)
+	p.DoParse()
+	assert.Equal([]map[string]any{{"id": "a", "title": "A", "site": "42"}}, p.ParsedData["jobs"])
+
+	cached, _ := store.Yaml(42)
+	again, _ := store.Yaml(42)
+	assert.Same(&cached[0], &again[0])
+
+	// a parent is changed
+	fsys["base.yaml"] = &fstest.MapFile{Data: []byte("jobs:\n  _locator: jobs\n  _index: ~\n"), ModTime: time.Unix(2, 0)}
+
+	p, err = store.NewJSONParser(42, []byte(`{"jobs": [{"id": "a", "title": "A"}]}`))
+	assert.Nil(err)
+	p.DoParse()
+	assert.Equal([]map[string]any{{"id": "a", "site": "42"}}, p.ParsedData["jobs"])
+
+	_, err = store.NewHTMLParser(43, nil)
+	assert.Error(err)
+}
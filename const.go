@@ -33,6 +33,8 @@ const (
 	//     key1: div.001
 	//     key2: div.002
 	//     key3: div.003
+	//
+	// html locators started with ":scope >" only match children of current node, like ":scope > div.meta > span"
 	Locator = "_locator"
 
 	// Raw represents the "_raw" configuration key.
@@ -47,6 +49,146 @@ const (
 	// but in some rare cases, there is no proper locator to use, so we have to use this to get prev elem
 	ExtractPrevElem = "_extract_prev"
 	ExtractParent   = "_extract_parent"
+
+	// ExtractNext, ExtractClosest, ExtractSiblings and ExtractChildren move matched nodes before _index is applied (html only)
+	// Values:
+	//   - true: the next sibling / all siblings / all children
+	//   - selector: the first next sibling / the closest ancestor (itself included) / siblings / children matched
+	// Example of definition list:
+	//   salary:
+	//     _locator: dt:contains("Salary")
+	//     _extract_next: dd
+	ExtractNext     = "_extract_next"
+	ExtractClosest  = "_extract_closest"
+	ExtractSiblings = "_extract_siblings"
+	ExtractChildren = "_extract_children"
+
+	// Nav is a list of navigation steps, applied in order before _index (html only)
+	// Steps:
+	//   - parent / {parent: n}
+	//   - next / {next: selector}
+	//   - prev / {prev: selector}
+	//   - {closest: selector}
+	//   - siblings / {siblings: selector}
+	//   - children / {children: selector}
+	//   - {find: selector}
+	//   - first / last
+	// Example:
+	//   _nav:
+	//     - closest: tr
+	//     - find: td.value
+	Nav = "_nav"
+
+	// Label finds the element whose text is the label, and returns its value node (html only)
+	// Layouts supported:
+	//   - <dt>Salary</dt><dd>100k</dd>
+	//   - <tr><th>Salary</th><td>100k</td></tr>
+	//   - <label for="salary">Salary</label> ... <input id="salary">
+	//   - <p><b>Salary:</b> 100k</p>
+	//   - <li>Salary: 100k</li>
+	// Formats:
+	//   _label: Salary
+	//   _label:
+	//     text: sal(ary)?
+	//     regex: true
+	//     ignore_case: true
+	// the trailing colon of label is ignored, and _locator (if set) limits where the label is searched
+	Label = "_label"
+
+	// Table reads a html table as a list of maps keyed by column headers, colspan and rowspan are expanded
+	// Formats:
+	//   _table: true
+	//   _table:
+	//     header: 0                    # index of header row, default is the first row of all th, or the first row
+	//     rename: {Job Title: title}  # rename headers
+	// child keys are optional, if set, only these columns are returned, and the config is applied to each cell:
+	//   salaries:
+	//     _locator: table.salary
+	//     _table: true
+	//     title:
+	//       _header: Job Title         # header of the column, default is the key
+	//     amount:
+	//       _type: i
+	Table       = "_table"
+	TableHeader = "_header"
+
+	// Text controls how text of a html leaf is extracted when _attr is not set,
+	// the default is selection.Text(), which glues block elements and keeps &nbsp; and zero-width chars
+	// Modes:
+	//   - inner: same as default
+	//   - visible: skip script/style and hidden elements (hidden, aria-hidden, display:none)
+	//   - block: add newline between block elements
+	//   - collapse: remove zero-width chars, and collapse whitespace (newlines are kept in block mode)
+	//   - unicode: NFKC, also NFC/NFD/NFKD
+	// Formats:
+	//   _text: collapse
+	//   _text: [visible, block, collapse, unicode: NFKC]
+	// the parser-wide default is __raw.text or Parser.BindTextMode
+	Text = "_text"
+
+	// CleanHTML is the allow-list of _attr: __clean_html, tags not allowed are unwrapped,
+	// script/style/iframe... are removed with their children, and href/src are made absolute
+	// Formats:
+	//   _attr: __clean_html
+	//   _clean_html:
+	//     tags: [p, ul, li, a]   # default is common text tags, like p/br/ul/ol/li/a/b/strong/h1-h6/table
+	//     attrs: [href]          # default is [href, src, alt, title, colspan, rowspan]
+	CleanHTML = "_clean_html"
+
+	// URLOptions controls how _type: url is normalized, the host is always lowercased
+	// Formats:
+	//   _type: url
+	//   _url:
+	//     strip_params: [utm_*, ref]  # default is common tracking params, like utm_*/gclid/fbclid
+	//     sort_query: false           # default is true
+	//     schemes: [http, https]      # "" is returned for other schemes, default is all allowed
+	// the parser-wide default is __raw.url,
+	// and the page base url is <base href>, or the source url (check WithSourceURL), or __raw.site_url
+	URLOptions = "_url"
+
+	// When makes a stub or leaf conditional, it's evaluated against the current node (the parent item),
+	// the key is skipped if the condition fails
+	// Formats:
+	//  > string: selector (html) or path (json) exists
+	//   _when: span.sponsored
+	//
+	//  > map: all conditions must match
+	//   _when:
+	//     exists: span.sponsored     # or a list of selectors
+	//     not_exists: span.expired
+	//     locator: a.company         # the node to check, default is the current node
+	//     attr: href                 # default is text (html only)
+	//     equals: /acme              # or contains: / matches: (regex)
+	//     preset: {country: CH}      # preset data equals
+	//     any: [cond1, cond2]        # at least one matches
+	//     not: cond
+	//
+	//  > list of configs: the first one whose _when matches (or without _when) is used
+	//   title:
+	//     - _when: span.sponsored
+	//       _locator: h3.ad-title
+	//     - _locator: h2.title
+	When = "_when"
+
+	// Fallback tries locators in order, and uses the first one with non-empty result
+	// a stub is non-empty if the node exists, and a leaf is non-empty if its text (or _attr) is not empty
+	// Example:
+	//   title:
+	//     _fallback:
+	//       - h1.title
+	//       - h2.title
+	//       - locator: meta[property="og:title"]
+	//         attr: content
+	// the first locator is used if none matched
+	Fallback = "_fallback"
+
+	// LocatorMode of a list _locator
+	// Values:
+	//   - all (default): results of all locators are used
+	//   - first: same as _fallback
+	LocatorMode      = "_locator_mode"
+	LocatorModeAll   = "all"
+	LocatorModeFirst = "first"
 )
 
 // Attribute related configuration keys
@@ -74,8 +216,34 @@ const (
 	//   - _attr_index: 0
 	AttrIndex = "_attr_index"
 
+	// AttrRegex refines value by regex, patterns are compiled when config is loaded
+	// Formats:
+	//   _attr_regex: \d+                 # the first match
+	//   _attr_regex:
+	//     pattern: id=(?P<id>\d+)
+	//     group: id                      # index or name of group, default is 0 (the whole match)
+	//     all: true                      # all matches as a list
+	//     replace: "$1"                  # replace all matches, group and all are ignored
 	AttrRegex = "_attr_regex"
 
+	// AttrLocale is the locale (like de-DE, fr, en-IN) of numbers, used by _type: i/f/salary,
+	// group/decimal separators and units like K/Mio/Tsd/万 are parsed by it, the parser-wide default is __raw.locale
+	// Example:
+	//   _locale: de-DE
+	//   _type: f   # "1.234,56 €" => 1234.56
+	AttrLocale = "_locale"
+
+	// AttrURLPart returns part of the url, relative url is resolved with the page base url first
+	// Values:
+	//   - scheme/host/path/query/fragment
+	//   - query.jk: value of query param jk
+	//   - path[1]: the 2nd segment of path, negative index counts from the end
+	//   - /job/{id}/{slug}: a map of named segments, "" if path not matched
+	// Example:
+	//   _attr: href
+	//   _url_part: query.jk
+	AttrURLPart = "_url_part"
+
 	// AttrPython runs Python script directly (requires Python environment)
 	// Example:
 	//   import sys
@@ -114,6 +282,17 @@ const (
 	Type = "_type"
 )
 
+// Config inheritance keys
+const (
+	// ConfigMerge controls how a map overrides the same map in parent config (__raw.extends or former yaml)
+	// Values:
+	//   - deep (default): merges key by key, and `key: ~` deletes existing key of parent (keys started with _ are kept as null)
+	//   - replace: replaces the map of parent as a whole
+	ConfigMerge  = "_merge"
+	MergeDeep    = "deep"
+	MergeReplace = "replace"
+)
+
 // Abbreviated keys
 const (
 	LocatorAbbr    = "_l"
@@ -143,6 +322,17 @@ const (
 	//         - ___.salarySnippet
 	PrefixLocatorStub = "___"
 
+	// Rank controls the rank of items of a stub, so ranks are unique across pages
+	// rank = start + ((page - 1) * per_page + rank in page) * step
+	// Format:
+	//   jobs:
+	//     _locator: div.job
+	//     _rank:
+	//       start: 1       # default is WithRankBase, or __raw.rank_start, or 0
+	//       step: 1
+	//       per_page: 20   # used with the page number set by WithPage (starts from 1)
+	Rank = "_rank"
+
 	// _prefixRefine defines the word we use as the prefix of method of attr refiner
 	_prefixRefine = "_refine"
 	// AttrJoinerSep is a separator used to join an array to string
@@ -159,6 +349,13 @@ const (
 	// AttrRawHTML returns the raw html of locator
 	AttrRawHTML = "__html"
 
+	// AttrMarkdown converts the inner html of locator to markdown, scripts, styles and hidden elements are skipped,
+	// and links are made absolute with the page base url
+	AttrMarkdown = "__markdown"
+
+	// AttrCleanHTML returns the inner html of locator with only allowed tags and attributes, check CleanHTML
+	AttrCleanHTML = "__clean_html"
+
 	// RefineWithKeyName uses key name as refiner method
 	// Example:
 	//   root:
@@ -178,4 +375,15 @@ const (
 	// Time types
 	AttrTypeT  = "t"  // Quick mode
 	AttrTypeT1 = "t1" // Search mode
+
+	// DateLayout is the output layout of _type: t/t1, it's a go layout or rfc3339/date/datetime,
+	// default is __raw.date_layout, or datetime (2006-01-02 15:04:05)
+	// relative dates like "Just posted", "yesterday", "30+ days ago" (en/de/fr/es/zh) are based on WithNow
+	DateLayout = "_date_layout"
+
+	// AttrTypeURL resolves relative url with the page base url and normalizes it, check URLOptions
+	AttrTypeURL = "url"
+
+	// AttrTypeSalary parses salary text to a map of min/max/currency/period/estimated, check ParseSalary
+	AttrTypeSalary = "salary"
 )
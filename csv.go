@@ -0,0 +1,325 @@
+package xparse
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	_rankKey = "rank"
+)
+
+type CSVOpts struct {
+	delimiter  rune
+	listJoiner string
+	header     bool
+	columns    []string
+}
+
+type CSVOptFunc func(o *CSVOpts)
+
+func bindCSVOpts(opt *CSVOpts, opts ...CSVOptFunc) {
+	for _, f := range opts {
+		f(opt)
+	}
+}
+
+// WithCSVDelimiter sets the field delimiter, "," by default
+func WithCSVDelimiter(r rune) CSVOptFunc {
+	return func(o *CSVOpts) {
+		o.delimiter = r
+	}
+}
+
+// WithCSVListJoiner sets the separator used to join list values into one cell, "|" by default
+func WithCSVListJoiner(s string) CSVOptFunc {
+	return func(o *CSVOpts) {
+		o.listJoiner = s
+	}
+}
+
+// WithCSVHeader writes the header row or not, true by default
+func WithCSVHeader(b bool) CSVOptFunc {
+	return func(o *CSVOpts) {
+		o.header = b
+	}
+}
+
+// WithCSVColumns only writes the columns given, in the order given
+func WithCSVColumns(columns []string) CSVOptFunc {
+	return func(o *CSVOpts) {
+		o.columns = columns
+	}
+}
+
+// DataAsCSV writes the items of stubKey as CSV rows to w.
+//
+//   - nested maps are flattened into dotted column names like "company.name"
+//   - list values are joined with the list joiner
+//   - columns keep the order in yaml config, keys not in yaml (like preset data) are appended in alphabetical order
+//   - rank is always the first column if existed
+func (p *Parser) DataAsCSV(stubKey string, w io.Writer, opts ...CSVOptFunc) error {
+	opt := CSVOpts{delimiter: ',', listJoiner: "|", header: true}
+	bindCSVOpts(&opt, opts...)
+
+	v, ok := p.ParsedData[stubKey]
+	if !ok {
+		return fmt.Errorf("cannot get data for key: %s", stubKey) //nolint
+	}
+
+	rows := dataAsRows(v)
+
+	columns := opt.columns
+	if len(columns) == 0 {
+		columns = p.csvColumns(stubKey, rows)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = opt.delimiter
+
+	if opt.header {
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		record := make([]string, 0, len(columns))
+		for _, col := range columns {
+			record = append(record, joinCellValues(valuesByDottedKey(row, col), opt.listJoiner))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// DataAsTSV is DataAsCSV with tab as delimiter
+func (p *Parser) DataAsTSV(stubKey string, w io.Writer, opts ...CSVOptFunc) error {
+	opts = append([]CSVOptFunc{WithCSVDelimiter('\t')}, opts...)
+	return p.DataAsCSV(stubKey, w, opts...)
+}
+
+func dataAsRows(data any) []map[string]any {
+	switch val := data.(type) {
+	case []map[string]any:
+		return val
+	case map[string]any:
+		return []map[string]any{val}
+	case []any:
+		var rows []map[string]any
+
+		for _, v := range val {
+			if m, ok := v.(map[string]any); ok {
+				rows = append(rows, m)
+			}
+		}
+
+		return rows
+	default:
+		return nil
+	}
+}
+
+// csvColumns returns the union of all rows' keys, ordered by yaml config
+func (p *Parser) csvColumns(stubKey string, rows []map[string]any) []string {
+	found := make(map[string]bool)
+
+	for _, row := range rows {
+		var keys []string
+		GetMapKeys(&keys, row)
+
+		for _, k := range keys {
+			found[k] = true
+		}
+	}
+
+	var columns []string
+
+	add := func(col string) {
+		if found[col] {
+			columns = append(columns, col)
+			delete(found, col)
+		}
+	}
+
+	add(_rankKey)
+
+	for _, key := range yamlKeyOrder([][]byte{p.resolvedYaml}, stubKey) {
+		add(key)
+
+		// keys generated from a map value, which are not in yaml, like "_locator: {a: x, b: y}"
+		var children []string
+
+		for col := range found {
+			if strings.HasPrefix(col, key+".") {
+				children = append(children, col)
+			}
+		}
+
+		sort.Strings(children)
+
+		for _, col := range children {
+			add(col)
+		}
+	}
+
+	var rest []string
+	for col := range found {
+		rest = append(rest, col)
+	}
+
+	sort.Strings(rest)
+
+	return append(columns, rest...)
+}
+
+// valuesByDottedKey returns all values of key like "company.name", values in lists are all returned
+func valuesByDottedKey(data any, key string) []any {
+	head, tail, nested := strings.Cut(key, ".")
+
+	switch val := data.(type) {
+	case map[string]any:
+		v, ok := val[head]
+		if !ok {
+			return nil
+		}
+
+		if !nested {
+			return []any{v}
+		}
+
+		return valuesByDottedKey(v, tail)
+	case []map[string]any:
+		var values []any
+		for _, v := range val {
+			values = append(values, valuesByDottedKey(v, key)...)
+		}
+
+		return values
+	case []any:
+		var values []any
+		for _, v := range val {
+			values = append(values, valuesByDottedKey(v, key)...)
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+func joinCellValues(values []any, joiner string) string {
+	var arr []string
+
+	for _, v := range values {
+		switch val := v.(type) {
+		case nil:
+			continue
+		case []any:
+			arr = append(arr, joinCellValues(val, joiner))
+		case []string:
+			arr = append(arr, strings.Join(val, joiner))
+		case map[string]any:
+			s, _ := Stringify(val)
+			arr = append(arr, s)
+		default:
+			arr = append(arr, cast.ToString(val))
+		}
+	}
+
+	return strings.Join(arr, joiner)
+}
+
+// yamlKeyOrder returns the dotted leaf keys of stubKey in the order they are written in yaml,
+// keys in later yaml are appended if not found in former ones
+func yamlKeyOrder(sources [][]byte, stubKey string) []string {
+	var (
+		keys []string
+		seen = make(map[string]bool)
+	)
+
+	for _, src := range sources {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(src, &doc); err != nil || len(doc.Content) == 0 {
+			continue
+		}
+
+		stub := yamlMappingValue(doc.Content[0], stubKey)
+		if stub == nil {
+			continue
+		}
+
+		for _, k := range yamlNodeKeys(stub, "") {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	node = resolveYamlAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveYamlAlias(node.Content[i+1])
+		}
+	}
+
+	return nil
+}
+
+func yamlNodeKeys(node *yaml.Node, prefix string) []string {
+	node = resolveYamlAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var keys []string
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		children := yamlNodeKeys(node.Content[i+1], name)
+		if len(children) == 0 {
+			keys = append(keys, name)
+		}
+
+		keys = append(keys, children...)
+	}
+
+	return keys
+}
+
+func resolveYamlAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
@@ -0,0 +1,56 @@
+package xparse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _csvHTML = `
+<ul>
+  <li class="job"><h2>Go Dev</h2><span class="co">Acme</span><i>go</i><i>sql</i></li>
+  <li class="job"><h2>Rust, Dev</h2><span class="co">Initech</span><i>rust</i></li>
+</ul>
+`
+
+const _csvYaml = `
+jobs:
+  _locator: li.job
+  _index: ~
+  title: h2
+  company:
+    _locator: span.co
+    name:
+  tags:
+    _locator: i
+    _index: ~
+  rank:
+    _attr_refine: bind_rank
+`
+
+func TestDataAsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	p := NewHTMLParser([]byte(_csvHTML), []byte(_csvYaml))
+	p.BindPresetData(map[string]any{"country": "CH"})
+	p.DoParse()
+
+	var buf bytes.Buffer
+
+	err := p.DataAsCSV("jobs", &buf)
+	assert.Nil(err)
+
+	want := `rank,title,company.name,tags,country
+0,Go Dev,Acme,go|sql,CH
+1,"Rust, Dev",Initech,rust,CH
+`
+	assert.Equal(want, buf.String())
+
+	buf.Reset()
+	err = p.DataAsTSV("jobs", &buf, WithCSVListJoiner(";"), WithCSVHeader(false), WithCSVColumns([]string{"title", "tags"}))
+	assert.Nil(err)
+	assert.Equal("Go Dev\tgo;sql\nRust, Dev\trust\n", buf.String())
+
+	assert.NotNil(p.DataAsCSV("not_existed", &buf))
+}
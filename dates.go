@@ -0,0 +1,213 @@
+package xparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coghost/xdtm"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// keys in __raw for dates
+//
+//	__raw:
+//	  timezone: Europe/Berlin
+//	  date_layout: rfc3339
+const (
+	_rawTimezone   = "__raw.timezone"
+	_rawDateLayout = "__raw.date_layout"
+)
+
+// _dateLayouts are aliases of layout in _date_layout
+var _dateLayouts = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"date":     time.DateOnly,
+	"datetime": time.DateTime,
+}
+
+var (
+	// _relativeDays are phrases of days before now, in en/de/fr/es/zh
+	_relativeDays = []struct {
+		regex *regexp.Regexp
+		days  int
+	}{
+		{regexp.MustCompile(`(?i)(just (posted|now)|gerade eben|soeben|à l'instant|ahora mismo|刚刚)`), 0},
+		{regexp.MustCompile(`(?i)(day before yesterday|vorgestern|avant-hier|anteayer|前天)`), 2},
+		{regexp.MustCompile(`(?i)(yesterday|gestern|\bhier\b|\bayer\b|昨天)`), 1},
+		{regexp.MustCompile(`(?i)(\btoday\b|\bheute\b|aujourd'hui|\bhoy\b|今天)`), 0},
+	}
+
+	// _clockRegex captures the time of day after _relativeDays, like "Today 10:30", "heute, 14:00 Uhr" or "yesterday 2:05 pm"
+	_clockRegex = regexp.MustCompile(`(?i)\b(\d{1,2})[:h](\d{2})(?::(\d{2}))?\s*([ap]\.?m\.?)?`)
+
+	// _agoRegexes capture the count and unit of phrases like "3 days ago", the count may be followed by + (30+ days ago)
+	_agoRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(\d+|an?|one)\+?\s*(second|sec|minute|min|hour|hr|day|week|month|year)s?\s+ago`),
+		regexp.MustCompile(`(?i)vor\s+(\d+|einem|einer)\+?\s*(sekunde|minute|stunde|tag|woche|monat|jahr)`),
+		regexp.MustCompile(`(?i)il y a\s+(\d+|une?)\+?\s*(seconde|minute|heure|jour|semaine|mois|an)`),
+		regexp.MustCompile(`(?i)hace\s+(\d+|una?)\+?\s*(segundo|minuto|hora|día|dia|semana|mes|año)`),
+		regexp.MustCompile(`(\d+)\+?\s*(秒|分钟|小时|天|周|个月|月|年)前`),
+	}
+
+	// _agoUnits are the units of _agoRegexes, lowercased
+	_agoUnits = map[string]string{
+		"second": "s", "sec": "s", "sekunde": "s", "seconde": "s", "segundo": "s", "秒": "s",
+		"minute": "m", "min": "m", "minuto": "m", "分钟": "m",
+		"hour": "h", "hr": "h", "stunde": "h", "heure": "h", "hora": "h", "小时": "h",
+		"day": "D", "tag": "D", "jour": "D", "día": "D", "dia": "D", "天": "D",
+		"week": "W", "woche": "W", "semaine": "W", "semana": "W", "周": "W",
+		"month": "M", "monat": "M", "mois": "M", "mes": "M", "个月": "M", "月": "M",
+		"year": "Y", "jahr": "Y", "an": "Y", "año": "Y", "年": "Y",
+	}
+)
+
+// BindNow sets the reference time of relative dates like "3 days ago", default is time.Now()
+func (p *Parser) BindNow(t time.Time) {
+	p.now = t
+}
+
+// BindTimezone sets the timezone of dates, default is the timezone parsed, or __raw.timezone
+func (p *Parser) BindTimezone(loc *time.Location) {
+	p.timezone = loc
+}
+
+func (p *Parser) refTime() time.Time {
+	now := p.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if p.timezone != nil {
+		now = now.In(p.timezone)
+	}
+
+	return now
+}
+
+// rawTimezone loads __raw.timezone, it's called once when config is loaded
+func (p *Parser) rawTimezone() *time.Location {
+	name := p.config.String(_rawTimezone)
+	if name == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(xpretty.Redf("invalid %s: %v", _rawTimezone, err))
+	}
+
+	return loc
+}
+
+// dateLayout returns _date_layout of cfg, or __raw.date_layout, or time.DateTime
+func (p *Parser) dateLayout(cfg map[string]any) string {
+	layout := cast.ToString(cfg[DateLayout])
+	if layout == "" {
+		layout = p.config.String(_rawDateLayout)
+	}
+
+	if v, ok := _dateLayouts[strings.ToLower(layout)]; ok {
+		return v
+	}
+
+	if layout == "" {
+		return time.DateTime
+	}
+
+	return layout
+}
+
+func (p *Parser) formatDate(raw any, bySearch bool, cfg map[string]any) any {
+	rawStr, _ := raw.(string)
+	now := p.refTime()
+
+	t, ok := relativeDate(rawStr, now)
+	if !ok {
+		c, err := xdtm.ToCarbon(rawStr, xdtm.WithBySearch(bySearch), xdtm.WithBaseTime(now))
+		if err != nil || c.Error != nil || c.StdTime().IsZero() {
+			return raw
+		}
+
+		t = c.StdTime()
+	}
+
+	if p.timezone != nil {
+		t = t.In(p.timezone)
+	}
+
+	return t.Format(p.dateLayout(cfg))
+}
+
+// relativeDate parses phrases like "Just posted", "yesterday" or "30+ days ago" in multiple languages
+func relativeDate(s string, now time.Time) (time.Time, bool) {
+	for _, rgx := range _agoRegexes {
+		m := rgx.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+
+		n, err := cast.ToIntE(m[1])
+		if err != nil {
+			// a/an/one/einem/une...
+			n = 1
+		}
+
+		switch _agoUnits[strings.ToLower(m[2])] {
+		case "s":
+			return now.Add(-time.Duration(n) * time.Second), true
+		case "m":
+			return now.Add(-time.Duration(n) * time.Minute), true
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), true
+		case "D":
+			return now.AddDate(0, 0, -n), true
+		case "W":
+			return now.AddDate(0, 0, -7*n), true
+		case "M":
+			return now.AddDate(0, -n, 0), true
+		case "Y":
+			return now.AddDate(-n, 0, 0), true
+		}
+	}
+
+	for _, rd := range _relativeDays {
+		if rd.regex.MatchString(s) {
+			return withClock(now.AddDate(0, 0, -rd.days), s), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// withClock sets the time of day of t to the clock time in s, t is returned as-is if none found
+func withClock(t time.Time, s string) time.Time {
+	m := _clockRegex.FindStringSubmatch(s)
+	if m == nil {
+		return t
+	}
+
+	// strconv, as cast takes 08 as octal
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.Atoi(m[3])
+
+	switch strings.ToLower(m[4][:min(len(m[4]), 1)]) {
+	case "p":
+		if hour < 12 {
+			hour += 12
+		}
+	case "a":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+
+	if hour > 23 || minute > 59 || sec > 59 {
+		return t
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, minute, sec, 0, t.Location())
+}
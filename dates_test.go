@@ -0,0 +1,80 @@
+package xparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeDates(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+just:
+  _locator: .just
+  _type: t
+ago:
+  _locator: .ago
+  _type: t
+  _date_layout: date
+yesterday:
+  _locator: .yesterday
+  _type: t
+  _date_layout: date
+de:
+  _locator: .de
+  _type: t
+  _date_layout: date
+zh:
+  _locator: .zh
+  _type: t
+  _date_layout: date
+hours:
+  _locator: .hours
+  _type: t
+absolute:
+  _locator: .absolute
+  _type: t
+clock:
+  _locator: .clock
+  _index: ~
+  _type: t
+`
+	html := `<p class="just">Just posted</p><p class="ago">Posted 30+ days ago</p>
+<p class="yesterday">Gestern</p><p class="de">vor 3 Tagen</p><p class="zh">5天前</p>
+<p class="hours">an hour ago</p><p class="absolute">2024-03-01 08:00</p>
+<p class="clock">Today 08:15</p><p class="clock">heute, 14:00 Uhr</p><p class="clock">yesterday 2:05 pm</p>`
+
+	now := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	p := NewHTMLParser([]byte(html), []byte(yml))
+	DoParse(p, WithNow(now), WithTimezone(time.UTC))
+
+	assert.Equal("2024-06-15 10:30:00", p.ParsedData["just"])
+	assert.Equal("2024-05-16", p.ParsedData["ago"])
+	assert.Equal("2024-06-14", p.ParsedData["yesterday"])
+	assert.Equal("2024-06-12", p.ParsedData["de"])
+	assert.Equal("2024-06-10", p.ParsedData["zh"])
+	assert.Equal("2024-06-15 09:30:00", p.ParsedData["hours"])
+	assert.Equal("2024-03-01 08:00:00", p.ParsedData["absolute"])
+	assert.Equal([]any{"2024-06-15 08:15:00", "2024-06-15 14:00:00", "2024-06-14 14:05:00"}, p.ParsedData["clock"])
+}
+
+func TestDateLayoutAndTimezone(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  timezone: Europe/Berlin
+  date_layout: rfc3339
+posted:
+  _locator: p
+  _type: t
+`
+	p := NewHTMLParser([]byte(`<p>today</p>`), []byte(yml))
+	p.BindNow(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	p.DoParse()
+
+	assert.Equal("2024-01-15T11:00:00+01:00", p.ParsedData["posted"])
+}
@@ -0,0 +1,381 @@
+package xparse
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keys in __raw to inherit from parent configs
+//
+//	__raw:
+//	  # parents are merged in order, then this file is merged on top of them
+//	  extends: [base.yaml, sites/ch.yaml]
+//	  # rename keys of parents, before this file is merged
+//	  rename:
+//	    jobs.title: name
+const (
+	_rawKey     = "__raw"
+	_rawExtends = "extends"
+	_rawRename  = "rename"
+)
+
+var (
+	ErrConfigCycle       = errors.New("config extends cycle")
+	ErrConfigKeyNotFound = errors.New("config key not found")
+	ErrConfigNoRoot      = errors.New("config root required")
+)
+
+// ResolveYaml merges sources in order, each source's __raw.extends is resolved from root first,
+// and returns the effective yaml, check ConfigMerge for the override semantics.
+// paths in extends are relative to the config file, or relative to root if the source is not a file,
+// root can be nil if no source has extends
+//
+// Note: sources are merged like extends, so in a later source `key: ~` deletes the key of former sources
+func ResolveYaml(root fs.FS, sources ...[]byte) ([]byte, error) {
+	r := &yamlResolver{root: root}
+
+	var acc *yaml.Node
+
+	for _, src := range sources {
+		node, err := r.resolve(src, ".")
+		if err != nil {
+			return nil, err
+		}
+
+		if acc, err = overlayYaml(acc, node); err != nil {
+			return nil, err
+		}
+	}
+
+	if acc == nil {
+		return nil, nil
+	}
+
+	stripYamlKey(acc, ConfigMerge)
+
+	return encodeYaml(acc), nil
+}
+
+// ResolveYamlFile reads name from root, and resolves it with ResolveYaml,
+// extends in the file are relative to the directory of name
+func ResolveYamlFile(root fs.FS, name string) ([]byte, error) {
+	resolved, _, err := resolveYamlFile(root, name)
+	return resolved, err
+}
+
+// resolveYamlFile resolves name, and returns the files read too
+func resolveYamlFile(root fs.FS, name string) ([]byte, []string, error) {
+	r := &yamlResolver{root: root}
+
+	node, err := r.resolveFile(name)
+	if err != nil {
+		return nil, r.files, err
+	}
+
+	if node, err = overlayYaml(nil, node); err != nil || node == nil {
+		return nil, r.files, err
+	}
+
+	stripYamlKey(node, ConfigMerge)
+
+	return encodeYaml(node), r.files, nil
+}
+
+// ResolvedConfig returns the effective yaml config, after extends, renames and deletions are resolved
+func (p *Parser) ResolvedConfig() []byte {
+	return p.resolvedYaml
+}
+
+type yamlResolver struct {
+	root fs.FS
+	// files being resolved, to detect cycles
+	stack []string
+	// files read
+	files []string
+}
+
+func (r *yamlResolver) resolveFile(name string) (*yaml.Node, error) {
+	for _, f := range r.stack {
+		if f == name {
+			return nil, fmt.Errorf("%w: %s -> %s", ErrConfigCycle, strings.Join(r.stack, " -> "), name)
+		}
+	}
+
+	if r.root == nil {
+		return nil, fmt.Errorf("%w: extends %s", ErrConfigNoRoot, name)
+	}
+
+	raw, err := fs.ReadFile(r.root, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read extends %s: %w", name, err)
+	}
+
+	r.files = append(r.files, name)
+	r.stack = append(r.stack, name)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	return r.resolve(raw, path.Dir(name))
+}
+
+// resolve parses raw, and merges it on top of its __raw.extends, dir is where extends are relative to
+func (r *yamlResolver) resolve(raw []byte, dir string) (*yaml.Node, error) {
+	node, err := parseYamlMapping(raw)
+	if err != nil || node == nil {
+		return node, err
+	}
+
+	extends, err := popRawSequence(node, _rawExtends)
+	if err != nil || len(extends) == 0 {
+		return node, err
+	}
+
+	var base *yaml.Node
+
+	for _, name := range extends {
+		parent, err := r.resolveFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if base, err = overlayYaml(base, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	return overlayYaml(base, node)
+}
+
+func parseYamlMapping(raw []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	node := normalizeYamlNode(doc.Content[0])
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config must be a map, but got %s", node.Tag)
+	}
+
+	return node, nil
+}
+
+// overlayYaml renames keys of dst by src's __raw.rename, then merges src on top of dst
+func overlayYaml(dst, src *yaml.Node) (*yaml.Node, error) {
+	if src == nil {
+		return dst, nil
+	}
+
+	renames := popRawMapping(src, _rawRename)
+
+	if dst == nil {
+		return src, nil
+	}
+
+	for i := 0; i+1 < len(renames); i += 2 {
+		if err := renameYamlKey(dst, renames[i].Value, renames[i+1].Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return mergeYamlNode(dst, src), nil
+}
+
+func mergeYamlNode(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil || dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return src
+	}
+
+	if mode := yamlMappingValue(src, ConfigMerge); mode != nil && mode.Value == MergeReplace {
+		return src
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if key.Value == ConfigMerge {
+			continue
+		}
+
+		at := yamlMappingIndex(dst, key.Value)
+
+		// key: ~ deletes an existing key, but _index: ~ is a valid option, and a new key: ~ is a null leaf
+		if value.Tag == "!!null" && !strings.HasPrefix(key.Value, "_") && at >= 0 {
+			dst.Content = append(dst.Content[:at], dst.Content[at+2:]...)
+			continue
+		}
+
+		if at >= 0 {
+			dst.Content[at+1] = mergeYamlNode(dst.Content[at+1], value)
+		} else {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+
+	return dst
+}
+
+// renameYamlKey renames the key of dotted path from to name
+func renameYamlKey(node *yaml.Node, from, name string) error {
+	segs := strings.Split(from, ".")
+
+	for _, seg := range segs[:len(segs)-1] {
+		if node = yamlMappingValue(node, seg); node == nil {
+			return fmt.Errorf("%w: rename %s", ErrConfigKeyNotFound, from)
+		}
+	}
+
+	at := yamlMappingIndex(node, segs[len(segs)-1])
+	if at < 0 {
+		return fmt.Errorf("%w: rename %s", ErrConfigKeyNotFound, from)
+	}
+
+	node.Content[at].Value = name
+
+	return nil
+}
+
+// popRawSequence removes __raw.key from node and returns its values, a single string is taken as a list
+func popRawSequence(node *yaml.Node, key string) ([]string, error) {
+	value := popRawKey(node, key)
+	if value == nil {
+		return nil, nil
+	}
+
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return []string{value.Value}, nil
+	case yaml.SequenceNode:
+		arr := make([]string, 0, len(value.Content))
+		for _, v := range value.Content {
+			arr = append(arr, v.Value)
+		}
+
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("%s.%s must be string or list", _rawKey, key)
+	}
+}
+
+// popRawMapping removes __raw.key from node and returns its key/value pairs
+func popRawMapping(node *yaml.Node, key string) []*yaml.Node {
+	value := popRawKey(node, key)
+	if value == nil || value.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	return value.Content
+}
+
+// popRawKey removes __raw.key from node, __raw is removed too if it's empty then
+func popRawKey(node *yaml.Node, key string) *yaml.Node {
+	raw := yamlMappingValue(node, _rawKey)
+	if raw == nil {
+		return nil
+	}
+
+	at := yamlMappingIndex(raw, key)
+	if at < 0 {
+		return nil
+	}
+
+	value := raw.Content[at+1]
+	raw.Content = append(raw.Content[:at], raw.Content[at+2:]...)
+
+	if len(raw.Content) == 0 {
+		at = yamlMappingIndex(node, _rawKey)
+		node.Content = append(node.Content[:at], node.Content[at+2:]...)
+	}
+
+	return value
+}
+
+func yamlMappingIndex(node *yaml.Node, key string) int {
+	if node.Kind != yaml.MappingNode {
+		return -1
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripYamlKey removes key from all maps in node
+func stripYamlKey(node *yaml.Node, key string) {
+	if node.Kind == yaml.MappingNode {
+		if at := yamlMappingIndex(node, key); at >= 0 {
+			node.Content = append(node.Content[:at], node.Content[at+2:]...)
+		}
+	}
+
+	for _, c := range node.Content {
+		stripYamlKey(c, key)
+	}
+}
+
+// normalizeYamlNode returns a deep copy of node, with aliases resolved and merge keys (<<) expanded,
+// so that nodes can be merged without side effects
+func normalizeYamlNode(node *yaml.Node) *yaml.Node {
+	node = resolveYamlAlias(node)
+
+	cp := *node
+	cp.Anchor = ""
+	cp.Content = nil
+
+	if node.Kind != yaml.MappingNode {
+		for _, c := range node.Content {
+			cp.Content = append(cp.Content, normalizeYamlNode(c))
+		}
+
+		return &cp
+	}
+
+	var inherited []*yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag != "!!merge" {
+			cp.Content = append(cp.Content, normalizeYamlNode(key), normalizeYamlNode(value))
+			continue
+		}
+
+		sources := []*yaml.Node{value}
+		if value = resolveYamlAlias(value); value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+
+		for _, src := range sources {
+			src = normalizeYamlNode(src)
+			for j := 0; j+1 < len(src.Content); j += 2 {
+				inherited = append(inherited, src.Content[j], src.Content[j+1])
+			}
+		}
+	}
+
+	// explicit keys override the inherited ones, and the first source wins among inherited ones
+	var merged []*yaml.Node
+
+	for i := 0; i+1 < len(inherited); i += 2 {
+		name := inherited[i].Value
+		if yamlMappingIndex(&cp, name) >= 0 || yamlMappingIndex(&yaml.Node{Kind: yaml.MappingNode, Content: merged}, name) >= 0 {
+			continue
+		}
+
+		merged = append(merged, inherited[i], inherited[i+1])
+	}
+
+	cp.Content = append(merged, cp.Content...)
+
+	return &cp
+}
@@ -0,0 +1,112 @@
+package xparse
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _extendsFS = fstest.MapFS{
+	"base.yaml": {Data: []byte(`
+__raw:
+  site: 1
+jobs:
+  _locator: data.jobs
+  _index: 0
+  title: title
+  company:
+    _locator: company
+    name: name
+    city: city
+  salary: &SAL
+    _locator: salary
+    _type: i
+`)},
+	"sites/ch.yaml": {Data: []byte(`
+__raw:
+  extends: ../base.yaml
+  rename:
+    jobs.title: name
+jobs:
+  _index: ~
+  company:
+    city: ~
+  salary:
+    _merge: replace
+    _locator: pay
+`)},
+	"loop/a.yaml": {Data: []byte("__raw:\n  extends: b.yaml\n")},
+	"loop/b.yaml": {Data: []byte("__raw:\n  extends: a.yaml\n")},
+}
+
+func TestResolveYamlFile(t *testing.T) {
+	assert := assert.New(t)
+
+	got, err := ResolveYamlFile(_extendsFS, "sites/ch.yaml")
+	assert.Nil(err)
+	assert.Equal(`__raw:
+  site: 1
+jobs:
+  _locator: data.jobs
+  _index: ~
+  name: title
+  company:
+    _locator: company
+    name: name
+  salary:
+    _locator: pay
+`, string(got))
+
+	_, err = ResolveYamlFile(_extendsFS, "loop/a.yaml")
+	assert.ErrorIs(err, ErrConfigCycle)
+}
+
+func TestResolveYamlSources(t *testing.T) {
+	assert := assert.New(t)
+
+	got, err := ResolveYaml(_extendsFS, []byte(`
+__raw:
+  extends: [base.yaml]
+jobs:
+  salary:
+    _type: f
+  extra: &EX
+    _locator: extra
+  more:
+    <<: *EX
+    _index: 1
+`), []byte(`
+__raw:
+  rename:
+    jobs.nothing: x
+`))
+	assert.Nil(got)
+	assert.ErrorIs(err, ErrConfigKeyNotFound)
+
+	// a null leaf only in the later source is kept, not taken as a deletion
+	got, err = ResolveYaml(_extendsFS,
+		[]byte("company:\n  _locator: div.c\n  title: span\n"),
+		[]byte("company:\n  name: span.name\n  city:\n"))
+	assert.Nil(err)
+	assert.Equal(`company:
+  _locator: div.c
+  title: span
+  name: span.name
+  city:
+`, string(got))
+
+	_, err = ResolveYaml(nil, []byte("__raw:\n  extends: base.yaml\n"))
+	assert.ErrorIs(err, ErrConfigNoRoot)
+
+	raw := []byte(`{"data": {"jobs": [{"title": "a", "pay": "10"}, {"title": "b", "pay": "20"}]}}`)
+	p := &JSONParser{NewParser(raw)}
+	p.LoadConfigFS(_extendsFS, []byte("__raw:\n  extends: sites/ch.yaml\n"), []byte("jobs:\n  salary:\n    _type: i\n"))
+	p.LoadRootSelection(raw)
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"name": "a", "company": map[string]any{"name": ""}, "salary": 10},
+		{"name": "b", "company": map[string]any{"name": ""}, "salary": 20},
+	}, p.ParsedData["jobs"], string(p.ResolvedConfig()))
+}
@@ -0,0 +1,129 @@
+package xparse
+
+import (
+	"strings"
+
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+const (
+	// _fallbackNone is the alternative index counted when no alternative matched
+	_fallbackNone = -1
+
+	_fallbackLocator = "locator"
+	_fallbackAttr    = "attr"
+)
+
+type fallbackAlt struct {
+	locator string
+	attr    string
+}
+
+// FallbackStats returns how many times each alternative of _fallback is used, keyed by yaml key path (like jobs.title),
+// and the count of no alternative matched is saved at index -1
+func (p *Parser) FallbackStats() map[string]map[int]int {
+	return p.fallbackStats
+}
+
+// resolveFallback returns a copy of cfg with _locator (and _attr) of the first non-empty alternative,
+// cfg is returned as-is if it has no _fallback.
+// hasValue extracts a leaf by the resolved cfg of an alternative, so _attr/_index/_nav/_text are respected
+func (p *Parser) resolveFallback(
+	key string, cfg map[string]any, node whenNode, hasValue func(resolved map[string]any) bool,
+) map[string]any {
+	arr, ok := cfgFallback(cfg)
+	if !ok {
+		return cfg
+	}
+
+	alts := make([]fallbackAlt, 0, len(arr))
+
+	for _, v := range arr {
+		switch v := v.(type) {
+		case string:
+			alts = append(alts, fallbackAlt{locator: v, attr: cast.ToString(cfg[Attr])})
+		case map[string]any:
+			alt := fallbackAlt{locator: cast.ToString(v[_fallbackLocator]), attr: cast.ToString(cfg[Attr])}
+			if attr, found := v[_fallbackAttr]; found {
+				alt.attr = cast.ToString(attr)
+			}
+
+			alts = append(alts, alt)
+		default:
+			panic(xpretty.Redf("alternative of %s must be string or map, but got (%T: %v)", key, v, v))
+		}
+	}
+
+	if len(alts) == 0 {
+		panic(xpretty.Redf("_fallback of %s is empty", key))
+	}
+
+	leaf := p.isLeaf(cfg)
+	chosen := _fallbackNone
+
+	for i, alt := range alts {
+		if !node.exists(alt.locator) {
+			continue
+		}
+
+		if !leaf || p.probe(func() bool { return hasValue(withFallbackAlt(cfg, alt)) }) {
+			chosen = i
+			break
+		}
+	}
+
+	p.countFallback(chosen)
+
+	alt := alts[0]
+	if chosen != _fallbackNone {
+		alt = alts[chosen]
+	}
+
+	return withFallbackAlt(cfg, alt)
+}
+
+// probe runs fn without tracing, so the alternatives tried are not reported as parsed
+func (p *Parser) probe(fn func() bool) bool {
+	tracer := p.tracer
+	p.tracer = nil
+
+	defer func() { p.tracer = tracer }()
+
+	return fn()
+}
+
+// withFallbackAlt returns a copy of cfg with _locator (and _attr) of alt
+func withFallbackAlt(cfg map[string]any, alt fallbackAlt) map[string]any {
+	resolved := make(map[string]any, len(cfg))
+
+	for k, v := range cfg {
+		switch k {
+		case Fallback, LocatorMode, LocatorAbbr:
+			continue
+		default:
+			resolved[k] = v
+		}
+	}
+
+	resolved[Locator] = alt.locator
+
+	if alt.attr != "" {
+		resolved[Attr] = alt.attr
+	}
+
+	return resolved
+}
+
+func (p *Parser) countFallback(alt int) {
+	if p.fallbackStats == nil {
+		p.fallbackStats = make(map[string]map[int]int)
+	}
+
+	keyPath := strings.Join(p.keyPath, ".")
+	if p.fallbackStats[keyPath] == nil {
+		p.fallbackStats[keyPath] = make(map[int]int)
+	}
+
+	p.fallbackStats[keyPath][alt]++
+}
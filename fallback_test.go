@@ -0,0 +1,97 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><head><meta property="og:title" content="From Meta"></head><body>
+<div class="job"><h1 class="title">H1 Title</h1></div>
+<div class="job"><h1 class="title"></h1><h2 class="title">H2 Title</h2></div>
+<div class="job"></div>
+</body></html>`
+
+	yml := `
+jobs:
+  _locator: div.job
+  _index: ~
+  title:
+    _fallback:
+      - h1.title
+      - h2.title
+  heading:
+    _locator: [h2.title, h1.title]
+    _locator_mode: first
+
+page:
+  title:
+    _fallback:
+      - h3.title
+      - locator: meta[property="og:title"]
+        attr: content
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "H1 Title", "heading": "H1 Title"},
+		{"title": "H2 Title", "heading": "H2 Title"},
+		{"title": "", "heading": ""},
+	}, p.ParsedData["jobs"])
+	assert.Equal(map[string]any{"title": "From Meta"}, p.ParsedData["page"])
+
+	assert.Equal(map[int]int{0: 1, 1: 1, -1: 1}, p.FallbackStats()["jobs.title"])
+	assert.Equal(map[int]int{1: 1}, p.FallbackStats()["page.title"])
+}
+
+func TestHTMLFallbackSpecialAttr(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body><div class="a"></div><div class="b"><p>Hi</p></div><ul><li></li><li>Two</li></ul></body></html>`
+
+	yml := `
+page:
+  desc:
+    _fallback: [div.a, div.b]
+    _attr: __html
+  item:
+    _fallback: [ul li, h1]
+    _index: 1
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(map[string]any{"desc": "<p>Hi</p>", "item": "Two"}, p.ParsedData["page"])
+	assert.Equal(map[int]int{1: 1}, p.FallbackStats()["page.desc"])
+	assert.Equal(map[int]int{0: 1}, p.FallbackStats()["page.item"])
+}
+
+func TestJSONFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	rawJSON := `{"jobs": [{"name": "A", "title": ""}, {"title": "B"}], "meta": {"id": 1}}`
+
+	yml := `
+jobs:
+  _locator: jobs
+  _index: ~
+  title:
+    _fallback: [title, name]
+
+meta:
+  _fallback: [info, meta]
+  id:
+    _locator: id
+    _type: i
+`
+	p := NewJSONParser([]byte(rawJSON), []byte(yml))
+	p.DoParse()
+
+	assert.Equal([]map[string]any{{"title": "A"}, {"title": "B"}}, p.ParsedData["jobs"])
+	assert.Equal(map[string]any{"id": 1}, p.ParsedData["meta"])
+	assert.Equal(map[int]int{0: 1, 1: 1}, p.FallbackStats()["jobs.title"])
+}
@@ -0,0 +1,373 @@
+package xparse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+	"golang.org/x/net/html"
+)
+
+// options of _clean_html, check CleanHTML for more info
+const (
+	_cleanTags  = "tags"
+	_cleanAttrs = "attrs"
+)
+
+var (
+	// _defaultCleanTags are kept by __clean_html, tags not listed are unwrapped (their children are kept)
+	_defaultCleanTags = []string{
+		"a", "b", "blockquote", "br", "code", "dd", "dl", "dt", "em", "h1", "h2", "h3", "h4", "h5", "h6",
+		"hr", "i", "li", "ol", "p", "pre", "strong", "table", "tbody", "td", "th", "thead", "tr", "u", "ul",
+	}
+	// _defaultCleanAttrs are kept by __clean_html, on* and style are always removed unless listed
+	_defaultCleanAttrs = []string{"href", "src", "alt", "title", "colspan", "rowspan"}
+
+	// _droppedTags are removed with their children
+	_droppedTags = map[string]bool{
+		"script": true, "style": true, "noscript": true, "template": true, "head": true,
+		"iframe": true, "object": true, "embed": true, "svg": true, "form": true, "button": true,
+	}
+
+	_voidTags = map[string]bool{"br": true, "hr": true, "img": true, "wbr": true, "col": true}
+
+	_urlAttrs = map[string]bool{"href": true, "src": true}
+
+	_blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+	_tightLinesRegex = regexp.MustCompile(`\n{2,}`)
+	_mdSpacesRegex   = regexp.MustCompile(`[\s\p{Z}]+`)
+)
+
+type cleanPolicy struct {
+	tags  map[string]bool
+	attrs map[string]bool
+	base  string
+}
+
+func (p *HTMLParser) newCleanPolicy(key string, cfg map[string]any) *cleanPolicy {
+	tags, attrs := _defaultCleanTags, _defaultCleanAttrs
+
+	switch opt := cfg[CleanHTML].(type) {
+	case nil:
+	case map[string]any:
+		if v, ok := opt[_cleanTags]; ok {
+			tags = cast.ToStringSlice(v)
+		}
+
+		if v, ok := opt[_cleanAttrs]; ok {
+			attrs = cast.ToStringSlice(v)
+		}
+	default:
+		panic(xpretty.Redf("%s of %s must be a map, but got (%T: %v)", CleanHTML, key, opt, opt))
+	}
+
+	return &cleanPolicy{tags: toSet(tags), attrs: toSet(attrs), base: p.pageBaseURL()}
+}
+
+// cleanHTML returns the inner html of selection, only allowed tags and attributes are kept,
+// and urls are made absolute
+func (p *HTMLParser) cleanHTML(key string, cfg map[string]any, selection *goquery.Selection) string {
+	policy := p.newCleanPolicy(key, cfg)
+
+	var buf strings.Builder
+
+	for _, n := range selection.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			policy.write(&buf, c)
+		}
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+func (c *cleanPolicy) write(buf *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if _droppedTags[n.Data] || isTrackingPixel(n) {
+		return
+	}
+
+	if !c.tags[n.Data] {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.write(buf, child)
+		}
+
+		return
+	}
+
+	buf.WriteString("<" + n.Data)
+
+	for _, a := range n.Attr {
+		if !c.attrs[a.Key] || strings.HasPrefix(a.Key, "on") {
+			continue
+		}
+
+		val := a.Val
+		if _urlAttrs[a.Key] {
+			if val = absoluteURL(c.base, val); val == "" {
+				continue
+			}
+		}
+
+		fmt.Fprintf(buf, ` %s="%s"`, a.Key, html.EscapeString(val))
+	}
+
+	buf.WriteString(">")
+
+	if _voidTags[n.Data] {
+		return
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.write(buf, child)
+	}
+
+	buf.WriteString("</" + n.Data + ">")
+}
+
+// markdown converts the inner html of selection to markdown
+func (p *HTMLParser) markdown(selection *goquery.Selection) string {
+	w := &markdownWriter{base: p.pageBaseURL()}
+
+	for _, n := range selection.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			w.node(c)
+		}
+	}
+
+	return w.String()
+}
+
+type markdownWriter struct {
+	base string
+	buf  strings.Builder
+	pre  bool
+}
+
+func (w *markdownWriter) String() string {
+	lines := strings.Split(w.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(_blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// children renders children of n with a new writer
+func (w *markdownWriter) children(n *html.Node) string {
+	sub := &markdownWriter{base: w.base, pre: w.pre}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sub.node(c)
+	}
+
+	return sub.String()
+}
+
+func (w *markdownWriter) text(s string) {
+	if w.pre {
+		w.buf.WriteString(s)
+		return
+	}
+
+	s = _mdSpacesRegex.ReplaceAllString(s, " ")
+
+	cur := w.buf.String()
+	if cur == "" || strings.HasSuffix(cur, "\n") || strings.HasSuffix(cur, " ") {
+		s = strings.TrimLeft(s, " ")
+	}
+
+	w.buf.WriteString(s)
+}
+
+func (w *markdownWriter) block(s string) {
+	if s = strings.TrimSpace(s); s == "" {
+		return
+	}
+
+	if w.buf.Len() != 0 {
+		w.buf.WriteString("\n\n")
+	}
+
+	w.buf.WriteString(s)
+	w.buf.WriteString("\n\n")
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if _droppedTags[n.Data] || isInvisible(n) || isTrackingPixel(n) {
+		return
+	}
+
+	switch n.Data {
+	case "br":
+		w.buf.WriteString("\n")
+	case "hr":
+		w.block("---")
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := cast.ToInt(n.Data[1:])
+		w.block(strings.Repeat("#", level) + " " + strings.ReplaceAll(w.children(n), "\n", " "))
+	case "p", "div", "section", "article", "header", "footer", "main", "aside", "dl":
+		w.block(w.children(n))
+	case "dt":
+		w.block("**" + w.children(n) + "**")
+	case "dd":
+		w.block(w.children(n))
+	case "strong", "b":
+		w.wrap(n, "**")
+	case "em", "i":
+		w.wrap(n, "*")
+	case "code":
+		w.wrap(n, "`")
+	case "a":
+		text, href := w.children(n), absoluteURL(w.base, attrValue(n, "href"))
+		if href == "" || text == "" {
+			w.text(text)
+			return
+		}
+
+		w.buf.WriteString(fmt.Sprintf("[%s](%s)", text, href))
+	case "img":
+		if src := absoluteURL(w.base, attrValue(n, "src")); src != "" {
+			w.buf.WriteString(fmt.Sprintf("![%s](%s)", attrValue(n, "alt"), src))
+		}
+	case "ul", "ol":
+		w.block(w.list(n, n.Data == "ol"))
+	case "li":
+		w.block("- " + w.children(n))
+	case "blockquote":
+		lines := strings.Split(w.children(n), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+
+		w.block(strings.Join(lines, "\n"))
+	case "pre":
+		sub := &markdownWriter{base: w.base, pre: true}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			sub.node(c)
+		}
+
+		w.block("```\n" + strings.Trim(sub.buf.String(), "\n") + "\n```")
+	case "table":
+		w.block(w.table(n))
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			w.node(c)
+		}
+	}
+}
+
+func (w *markdownWriter) wrap(n *html.Node, mark string) {
+	s := w.children(n)
+	if s == "" {
+		return
+	}
+
+	w.buf.WriteString(mark + s + mark)
+}
+
+// list renders li of n, continuation lines of each item are indented under the marker
+func (w *markdownWriter) list(n *html.Node, ordered bool) string {
+	var items []string
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", len(items)+1)
+		}
+
+		// items are tight, so nested lists are not separated by blank lines
+		lines := strings.Split(_tightLinesRegex.ReplaceAllString(w.children(c), "\n"), "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = strings.Repeat(" ", len(marker)) + lines[i]
+			}
+		}
+
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func (w *markdownWriter) table(n *html.Node) string {
+	var rows []string
+
+	for _, row := range tableGrid(goquery.NewDocumentFromNode(n).Selection) {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, strings.ReplaceAll(w.children(cell.Nodes[0]), "\n", " "))
+		}
+
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+
+		if len(rows) == 1 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// isTrackingPixel returns true for images of 1x1 (or 0x0)
+func isTrackingPixel(n *html.Node) bool {
+	if n.Data != "img" {
+		return false
+	}
+
+	return cast.ToInt(attrValue(n, "width")) <= 1 && cast.ToInt(attrValue(n, "height")) <= 1 &&
+		(attrValue(n, "width") != "" || attrValue(n, "height") != "")
+}
+
+// absoluteURL resolves ref with base, "" is returned for javascript: or invalid urls
+func absoluteURL(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	u := resolveURL(base, ref)
+	if u == nil {
+		return ""
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto", "tel":
+		return u.String()
+	default:
+		return ""
+	}
+}
+
+func toSet(arr []string) map[string]bool {
+	set := make(map[string]bool, len(arr))
+	for _, v := range arr {
+		set[v] = true
+	}
+
+	return set
+}
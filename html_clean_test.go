@@ -0,0 +1,74 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _cleanHTML = `<html><body>
+<div class="desc" style="color: red">
+  <h2>About   the job</h2>
+  <p onclick="track()">We are <b>hiring</b> a <a href="/apply?id=1" class="btn">Go developer</a>.</p>
+  <script>var t = 1;</script>
+  <img src="/pixel.gif" width="1" height="1">
+  <ul><li>Go</li><li>SQL <ul><li>PostgreSQL</li></ul></li></ul>
+  <p style="display:none">hidden</p>
+  <a href="javascript:alert(1)">bad</a>
+</div>
+</body></html>`
+
+func TestHTMLMarkdown(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  site_url: https://example.com/jobs/
+desc:
+  _locator: div.desc
+  _attr: __markdown
+`
+	p := NewHTMLParser([]byte(_cleanHTML), []byte(yml))
+	p.DoParse()
+
+	want := "## About the job\n\n" +
+		"We are **hiring** a [Go developer](https://example.com/apply?id=1).\n\n" +
+		"- Go\n- SQL\n  - PostgreSQL\n\n" +
+		"bad"
+	assert.Equal(want, p.ParsedData["desc"])
+}
+
+func TestHTMLCleanHTML(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  site_url: https://example.com/jobs/
+desc:
+  _locator: div.desc p
+  _index: 0
+  _attr: __clean_html
+only_text:
+  _locator: div.desc p
+  _index: 0
+  _attr: __clean_html
+  _clean_html:
+    tags: [p]
+`
+	p := NewHTMLParser([]byte(_cleanHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(`We are <b>hiring</b> a <a href="https://example.com/apply?id=1">Go developer</a>.`, p.ParsedData["desc"])
+	assert.Equal(`We are hiring a Go developer.`, p.ParsedData["only_text"])
+
+	p = NewHTMLParser([]byte(_cleanHTML), []byte(`desc: {_locator: div.desc, _attr: __clean_html}`))
+	p.DoParse()
+
+	got := p.ParsedData["desc"].(string)
+	assert.NotContains(got, "script")
+	assert.NotContains(got, "pixel")
+	assert.NotContains(got, "style")
+	assert.NotContains(got, "onclick")
+	assert.NotContains(got, "javascript")
+	assert.Contains(got, `<a href="/apply?id=1">Go developer</a>`)
+}
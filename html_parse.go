@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/coghost/xpretty"
@@ -31,29 +32,31 @@ func (p *HTMLParser) Spawn(raw []byte, ymlCfg ...[]byte) {
 }
 
 func (p *HTMLParser) LoadRootSelection(raw []byte) {
+	p.sourceData = raw
+
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
 	PanicIfErr(err)
 
 	p.Root = doc.Selection
+	p.baseHref = doc.Find("base[href]").First().AttrOr("href", "")
 }
 
 func (p *HTMLParser) DoParse() {
 	p.runCheck()
 
 	for key, cfg := range p.config.Data() {
-		switch cfgType := cfg.(type) {
-		case map[string]any:
-			p.rankOffset = 0
-			selection, _ := p.Root.(*goquery.Selection)
-			p.parseDom(key, cfgType, selection, p.ParsedData, _layerForRank)
-		default:
+		if !isStubConfig(cfg) {
 			xpretty.RedPrintf(_nonMapHint, key, cfg)
 			continue
 		}
+
+		p.rankOffset = 0
+		selection, _ := p.Root.(*goquery.Selection)
+		p.parseDom(key, cfg, selection, p.ParsedData, _layerForRank)
 	}
 
 	p.PostDoParse()
-	p.RefineJobsWithPreset()
+	p.RefineStubsWithPreset()
 }
 
 // parseDom
@@ -64,6 +67,11 @@ func (p *HTMLParser) parseDom(key string, cfg any, selection *goquery.Selection,
 	p.appendNestedKeys(key)
 	defer p.popNestedKeys()
 
+	p.pushOutputKey(key)
+	defer p.popOutputKey()
+
+	p.traceStub()
+
 	b := p.isRequiredKey(key)
 	// xpretty.DummyLog(key, p.testKeys, b, p.forceParsedKey, p.nestedKeys)
 	if !b {
@@ -71,7 +79,10 @@ func (p *HTMLParser) parseDom(key string, cfg any, selection *goquery.Selection,
 	}
 
 	if funk.IsEmpty(cfg) {
+		p.beginLeaf()
 		data[key] = p.getSelectionAttr(key, map[string]any{key: ""}, selection)
+		p.commitLeaf(data[key], p.htmlLocations(nil, selection)...)
+
 		return
 	}
 
@@ -80,14 +91,34 @@ func (p *HTMLParser) parseDom(key string, cfg any, selection *goquery.Selection,
 		// the recursive end condition
 		p.handleStr(key, v, selection, data)
 	case map[string]any:
+		if !p.whenAllowed(key, v, htmlWhenNode{selection}) {
+			return
+		}
+
 		p.handleMap(key, v, selection, data, layer)
+	case []any:
+		picked, ok := p.pickCase(key, v, htmlWhenNode{selection})
+		if !ok {
+			return
+		}
+
+		p.handleMap(key, picked, selection, data, layer)
 	default:
 		panic(xpretty.Redf("unknown type of (%v:%v), only support (1:string or 2:map[string]any)", key, cfg))
 	}
 }
 
 func (p *HTMLParser) handleStr(key string, sel string, selection *goquery.Selection, data map[string]any) {
-	data[key] = selection.Find(sel).First().Text()
+	p.beginLeaf()
+
+	start := time.Now()
+	elems := findLocator(selection, sel)
+	p.traceLocator(sel, len(elems.Nodes), start)
+
+	elem := elems.First()
+	data[key] = p.selectionText(key, nil, elem)
+
+	p.commitLeaf(data[key], p.htmlLocations(sel, elem)...)
 }
 
 // handleMap
@@ -101,6 +132,15 @@ func (p *HTMLParser) handleMap(
 	data map[string]any,
 	layer int,
 ) {
+	cfg = p.resolveFallback(key, cfg, htmlWhenNode{selection}, func(resolved map[string]any) bool {
+		return p.hasValue(key, resolved, selection)
+	})
+
+	if _, ok := cfg[Table]; ok {
+		p.handleTable(key, cfg, selection, data, layer)
+		return
+	}
+
 	if p.isLeaf(cfg) {
 		p.getNodesAttrs(key, cfg, selection, data)
 		return
@@ -116,7 +156,7 @@ func (p *HTMLParser) handleMap(
 	case []*goquery.Selection:
 		var allSubData []map[string]any
 
-		for _, selection := range dom {
+		for i, selection := range dom {
 			if layer == _layerForRank {
 				p.FocusedStub = selection
 			}
@@ -129,7 +169,9 @@ func (p *HTMLParser) handleMap(
 			subData := make(map[string]any)
 			allSubData = append(allSubData, subData)
 
+			p.pushOutputIndex(i)
 			p.parseDomNodes(cfg, selection, subData)
+			p.popOutputIndex()
 		}
 
 		data[key] = allSubData
@@ -203,6 +245,9 @@ func (p *HTMLParser) getAllElems(key string,
 
 			res, _ := p.getOneSelector(key, subCfg, cfg, backup)
 			dat[dataKey], _ = res.(*goquery.Selection)
+
+			sel, _ := subCfg.(string)
+			p.recordHTMLAlt(sel, -1, res)
 		}
 
 		iface = dat
@@ -236,11 +281,13 @@ func (p *HTMLParser) getElemsOneByOne(key string, selArr []string,
 
 	backup := selection
 
-	for _, v := range selArr {
+	for i, v := range selArr {
 		v1, backup := p.handleStub(v, backup)
 		v, _ = v1.(string)
 
 		elem, _ := p.getOneSelector(key, v, cfg, backup)
+		p.recordHTMLAlt(v, i, elem)
+
 		switch val := elem.(type) {
 		case *goquery.Selection:
 			resArr = append(resArr, val)
@@ -252,16 +299,48 @@ func (p *HTMLParser) getElemsOneByOne(key string, selArr []string,
 	return resArr
 }
 
+// _scopeLocator is the prefix of locators relative to current selection, check findLocator
+const _scopeLocator = ":scope"
+
+// findLocator is selection.Find, and a locator started with ":scope >" matches the children of selection only,
+// like ":scope > div.meta > span", only child combinators are supported after :scope
+func findLocator(selection *goquery.Selection, locator string) *goquery.Selection {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(locator), _scopeLocator)
+	if !ok {
+		return selection.Find(locator)
+	}
+
+	steps := strings.Split(rest, ">")
+	if strings.TrimSpace(steps[0]) != "" {
+		panic(xpretty.Redf("%s must be followed by >, but got (%s)", _scopeLocator, locator))
+	}
+
+	elems := selection
+	for _, step := range steps[1:] {
+		elems = elems.ChildrenFiltered(strings.TrimSpace(step))
+	}
+
+	return elems
+}
+
 func (p *HTMLParser) getOneSelector(key string, sel any,
 	cfg map[string]any, selection *goquery.Selection,
-) (any, bool) {
+) (iface any, isComplexSel bool) {
 	selStr, _ := sel.(string)
-	elems := selection.Find(selStr)
+
+	start := time.Now()
+	elems := findLocator(selection, selStr)
+	p.traceLocator(selStr, len(elems.Nodes), start)
+
+	elems = p.navigate(key, cfg, elems)
+
+	defer p.traceIndex(cfg, &iface)
+
 	index := mustCfgIndex(cfg)
 
-	isComplexSel := strings.Contains(selStr, ",")
+	isComplexSel = strings.Contains(selStr, ",")
 
-	iface := p.handleNullIndexOnly(key, isComplexSel, cfg, elems)
+	iface = p.handleNullIndexOnly(key, isComplexSel, cfg, elems)
 	if iface != nil {
 		return iface, isComplexSel
 	}
@@ -409,13 +488,26 @@ func (p *HTMLParser) getNodesAttrs(
 	selection *goquery.Selection,
 	data map[string]any,
 ) {
+	p.beginLeaf()
+
 	// first of all, check if _raw is set or not.
 	if val := mustCfgRaw(cfg); val != nil && val != "" {
 		data[key] = p.convertToType(val, cfg)
+		p.commitLeaf(data[key])
+
+		return
+	}
+
+	if _, ok := cfg[Label]; ok {
+		data[key] = p.getLabelValue(key, cfg, selection)
+		p.commitLeaf(data[key])
+
 		return
 	}
 
 	elems, complexSel := p.getAllElems(key, cfg, selection)
+	locs := p.htmlLocations(mustCfgLocator(cfg), elems)
+	defer func() { p.commitLeaf(data[key], locs...) }()
 
 	switch dom := elems.(type) {
 	case *goquery.Document:
@@ -504,7 +596,7 @@ func (p *HTMLParser) getSelectionSliceAttr(key string, cfg map[string]any, resul
 	// joiner := p.getJoinerOr(cfg, AttrJoinerSep)
 	// v := p.refineAttr(key, strings.Join(resArr, joiner), cfg, resultArr)
 	v := p.refineAttr(key, resArr, cfg, resultArr)
-	v = p.advancedPostRefineAttr(v, cfg)
+	v = p.advancedPostRefineAttr(key, v, cfg)
 
 	return p.convertToType(v, cfg)
 }
@@ -519,7 +611,7 @@ func (p *HTMLParser) getSelectionMapAttr(key string, cfg map[string]any, results
 
 	str, _ := Stringify(dat)
 	v := p.refineAttr(key, str, cfg, results)
-	v = p.advancedPostRefineAttr(v, cfg)
+	v = p.advancedPostRefineAttr(key, v, cfg)
 
 	return p.convertToType(v, cfg)
 }
@@ -528,16 +620,38 @@ func (p *HTMLParser) getSelectionAttr(key string, cfg map[string]any, selection
 	raw := p.getRawAttr(cfg, selection)
 	raw = p.stripChars(key, raw, cfg)
 	raw = p.refineAttr(key, raw, cfg, selection)
-	raw = p.advancedPostRefineAttr(raw, cfg)
+	raw = p.advancedPostRefineAttr(key, raw, cfg)
 
 	return p.convertToType(raw, cfg)
 }
 
+// hasValue returns true if the raw value of any node located by cfg is not empty
+func (p *HTMLParser) hasValue(key string, cfg map[string]any, selection *goquery.Selection) bool {
+	elems, _ := p.getAllElems(key, cfg, selection)
+
+	var arr []*goquery.Selection
+
+	switch dom := elems.(type) {
+	case *goquery.Selection:
+		arr = append(arr, dom)
+	case []*goquery.Selection:
+		arr = dom
+	}
+
+	for _, elem := range arr {
+		if elem.Length() != 0 && !funk.IsEmpty(p.getRawAttr(cfg, elem)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *HTMLParser) getRawAttr(cfg map[string]any, selection *goquery.Selection) any {
 	attr := cfg[Attr]
 
 	if attr == nil {
-		v := selection.Text()
+		v := p.selectionText("", cfg, selection)
 		return p.TrimSpace(v, cfg)
 	}
 
@@ -550,6 +664,14 @@ func (p *HTMLParser) getRawAttr(cfg map[string]any, selection *goquery.Selection
 		return v
 	}
 
+	if attr == AttrMarkdown {
+		return p.markdown(selection)
+	}
+
+	if attr == AttrCleanHTML {
+		return p.cleanHTML("", cfg, selection)
+	}
+
 	if attr == AttrJoinElemsText {
 		var arr []string
 
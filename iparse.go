@@ -1,5 +1,7 @@
 package xparse
 
+import "time"
+
 type IDev interface {
 	ToggleDevMode(b bool)
 	VerifyKeys() []string
@@ -45,7 +47,34 @@ func DoParse(parser IParser, opts ...ParseOptFunc) any {
 	bindParseOpts(opt, opts...)
 
 	parser.BindPresetData(opt.preset)
+
+	if b, ok := parser.(interface{ BindSourceURL(u string) }); ok && opt.sourceURL != "" {
+		b.BindSourceURL(opt.sourceURL)
+	}
+
+	if b, ok := parser.(interface{ BindNow(t time.Time) }); ok && !opt.now.IsZero() {
+		b.BindNow(opt.now)
+	}
+
+	if b, ok := parser.(interface{ BindTimezone(loc *time.Location) }); ok && opt.timezone != nil {
+		b.BindTimezone(opt.timezone)
+	}
+
+	if b, ok := parser.(interface{ BindRankBase(n int) }); ok && opt.rankBase != nil {
+		b.BindRankBase(*opt.rankBase)
+	}
+
+	if b, ok := parser.(interface{ BindPage(n int) }); ok && opt.page > 0 {
+		b.BindPage(opt.page)
+	}
+
 	parser.ToggleDevMode(true)
+
+	// optional, so parsers implemented outside are not broken
+	if b, ok := parser.(interface{ BindTracer(t Tracer) }); ok && opt.tracer != nil {
+		b.BindTracer(opt.tracer)
+	}
+
 	UpdateRefiners(parser, WithRefPromptConfig(opt.promptCfg))
 	parser.DoParse()
 	// parser.PostDoParse()
@@ -58,6 +87,12 @@ type ParseOpts struct {
 	preset      map[string]any
 	rootKey     string
 	promptCfg   *PromptConfig
+	tracer      Tracer
+	sourceURL   string
+	now         time.Time
+	timezone    *time.Location
+	rankBase    *int
+	page        int
 }
 
 type ParseOptFunc func(o *ParseOpts)
@@ -92,3 +127,45 @@ func WithPromptConfig(cfg *PromptConfig) ParseOptFunc {
 		o.promptCfg = cfg
 	}
 }
+
+// WithTracer: used to explain how each field is parsed, check Tracer for more info
+func WithTracer(t Tracer) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.tracer = t
+	}
+}
+
+// WithSourceURL: used to resolve relative urls of page, check BindSourceURL
+func WithSourceURL(u string) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.sourceURL = u
+	}
+}
+
+// WithNow: used as the reference time of relative dates like "3 days ago"
+func WithNow(t time.Time) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.now = t
+	}
+}
+
+// WithTimezone: used as the timezone of dates
+func WithTimezone(loc *time.Location) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.timezone = loc
+	}
+}
+
+// WithRankBase: used as the rank of the first item, like the count of items of former pages
+func WithRankBase(n int) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.rankBase = &n
+	}
+}
+
+// WithPage: used as the current page number (starts from 1), ranks are offset by _rank.per_page of former pages
+func WithPage(n int) ParseOptFunc {
+	return func(o *ParseOpts) {
+		o.page = n
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/coghost/xpretty"
 	"github.com/spf13/cast"
@@ -31,6 +32,7 @@ func (p *JSONParser) Spawn(raw []byte, ymlCfg ...[]byte) {
 }
 
 func (p *JSONParser) LoadRootSelection(raw []byte) {
+	p.sourceData = raw
 	p.RawData = string(raw)
 	p.Root = gjson.Parse(string(raw))
 }
@@ -39,25 +41,29 @@ func (p *JSONParser) DoParse() {
 	p.runCheck()
 
 	for key, cfg := range p.config.Data() {
-		switch cfgType := cfg.(type) {
-		case map[string]any:
-			p.rankOffset = 0
-			result, _ := p.Root.(gjson.Result)
-			p.parseDom(key, cfgType, result, p.ParsedData, _layerForRank)
-		default:
+		if !isStubConfig(cfg) {
 			fmt.Fprint(os.Stderr, xpretty.Redf(_nonMapHint, key, cfg))
 			continue
 		}
+
+		p.rankOffset = 0
+		result, _ := p.Root.(gjson.Result)
+		p.parseDom(key, cfg, result, p.ParsedData, _layerForRank)
 	}
 
 	p.PostDoParse()
-	p.RefineJobsWithPreset()
+	p.RefineStubsWithPreset()
 }
 
 func (p *JSONParser) parseDom(key string, cfg any, result gjson.Result, data map[string]any, layer int) {
 	p.appendNestedKeys(key)
 	defer p.popNestedKeys()
 
+	p.pushOutputKey(key)
+	defer p.popOutputKey()
+
+	p.traceStub()
+
 	b := p.isRequiredKey(key)
 	// xpretty.DummyLog(key, p.testKeys, b, p.forceParsedKey, p.nestedKeys)
 	if !b {
@@ -65,7 +71,10 @@ func (p *JSONParser) parseDom(key string, cfg any, result gjson.Result, data map
 	}
 
 	if funk.IsEmpty(cfg) {
+		p.beginLeaf()
 		data[key] = p.getSelectionAttr(key, map[string]any{key: ""}, result)
+		p.commitLeaf(data[key], p.jsonLocation("", -1, result))
+
 		return
 	}
 
@@ -74,7 +83,18 @@ func (p *JSONParser) parseDom(key string, cfg any, result gjson.Result, data map
 		// the recursive end condition
 		p.handleStr(key, v, result, data)
 	case map[string]any:
+		if !p.whenAllowed(key, v, jsonWhenNode{result}) {
+			return
+		}
+
 		p.handleMap(key, v, result, data, layer)
+	case []any:
+		picked, ok := p.pickCase(key, v, jsonWhenNode{result})
+		if !ok {
+			return
+		}
+
+		p.handleMap(key, picked, result, data, layer)
 	default:
 		panic(xpretty.Redf("unknown type of (%v:%v), only support (1:string or 2:map[string]any)", key, cfg))
 	}
@@ -85,7 +105,7 @@ func (p *JSONParser) getSelectionAttr(key string, cfg map[string]any, result gjs
 	raw = result.String()
 	raw = p.stripChars(key, raw, cfg)
 	raw = p.refineAttr(key, raw, cfg, result)
-	raw = p.advancedPostRefineAttr(raw, cfg)
+	raw = p.advancedPostRefineAttr(key, raw, cfg)
 
 	return p.convertToType(raw, cfg)
 }
@@ -98,7 +118,7 @@ func (p *JSONParser) getSelectionSliceAttr(key string, cfg map[string]any, resul
 
 	joiner := p.getJoinerOrDefault(cfg, AttrJoinerSep)
 	v := p.refineAttr(key, strings.Join(raw, joiner), cfg, resultArr)
-	v = p.advancedPostRefineAttr(v, cfg)
+	v = p.advancedPostRefineAttr(key, v, cfg)
 
 	return p.convertToType(v, cfg)
 }
@@ -111,13 +131,21 @@ func (p *JSONParser) getSelectionMapAttr(key string, cfg map[string]any, results
 
 	str, _ := Stringify(dat)
 	v := p.refineAttr(key, str, cfg, results)
-	v = p.advancedPostRefineAttr(v, cfg)
+	v = p.advancedPostRefineAttr(key, v, cfg)
 
 	return p.convertToType(v, cfg)
 }
 
 func (p *JSONParser) handleStr(key string, sel string, result gjson.Result, data map[string]any) {
-	data[key] = result.Get(sel).String()
+	p.beginLeaf()
+
+	start := time.Now()
+	res := result.Get(sel)
+	p.traceLocator(sel, countMatches(res), start)
+
+	data[key] = res.String()
+
+	p.commitLeaf(data[key], p.jsonLocation(sel, -1, res))
 }
 
 func (p *JSONParser) handleMap(
@@ -127,6 +155,10 @@ func (p *JSONParser) handleMap(
 	data map[string]any,
 	layer int,
 ) {
+	cfg = p.resolveFallback(key, cfg, jsonWhenNode{result}, func(resolved map[string]any) bool {
+		return p.hasValue(key, resolved, result)
+	})
+
 	if p.isLeaf(cfg) {
 		p.getNodesAttrs(key, cfg, result, data)
 		return
@@ -146,7 +178,7 @@ func (p *JSONParser) handleMap(
 	case []gjson.Result:
 		var allSubData []map[string]any
 
-		for _, result := range dom {
+		for i, result := range dom {
 			if layer == _layerForRank {
 				p.FocusedStub = result
 			}
@@ -159,7 +191,9 @@ func (p *JSONParser) handleMap(
 			subData := make(map[string]any)
 			allSubData = append(allSubData, subData)
 
+			p.pushOutputIndex(i)
 			p.parseDomNodes(cfg, result, subData)
+			p.popOutputIndex()
 		}
 
 		data[key] = allSubData
@@ -183,13 +217,15 @@ func (p *JSONParser) getAllElems(key string, cfg map[string]any, result gjson.Re
 
 	switch sel := sel.(type) {
 	case string:
+		start := time.Now()
+
 		if sel == JSONArrayRootLocator {
 			result = gjson.Parse(p.RawData)
 		} else {
 			result = result.Get(sel)
 		}
 
-		iface = p.getOneSelector(key, sel, cfg, result)
+		iface = p.getOneSelector(key, sel, cfg, result, start)
 	case []any:
 		arr := []gjson.Result{}
 		backup := result
@@ -197,9 +233,10 @@ func (p *JSONParser) getAllElems(key string, cfg map[string]any, result gjson.Re
 		for _, v := range sel {
 			v, backup = p.handleStub(v, backup)
 			v1, _ := v.(string)
+			start := time.Now()
 			result = backup.Get(v1)
 
-			res := p.getOneSelector(key, v, cfg, result)
+			res := p.getOneSelector(key, v, cfg, result, start)
 			gRes, _ := res.(gjson.Result)
 			arr = append(arr, gRes)
 		}
@@ -213,9 +250,10 @@ func (p *JSONParser) getAllElems(key string, cfg map[string]any, result gjson.Re
 		for selK, v := range sel {
 			v, backup = p.handleStub(v, backup)
 			v1, _ := v.(string)
+			start := time.Now()
 			result = backup.Get(v1)
 
-			res := p.getOneSelector(key, v, cfg, result)
+			res := p.getOneSelector(key, v, cfg, result, start)
 			gRes, _ := res.(gjson.Result)
 
 			dat[selK] = gRes
@@ -242,7 +280,15 @@ func (p *JSONParser) handleStub(raw any, result gjson.Result) (any, gjson.Result
 	return raw, result
 }
 
-func (p *JSONParser) getOneSelector(key string, sel any, cfg map[string]any, result gjson.Result) (iface any) { //nolint
+// getOneSelector picks result by _index, start is the time before result is located, for tracing
+func (p *JSONParser) getOneSelector( //nolint
+	key string, sel any, cfg map[string]any, result gjson.Result, start time.Time,
+) (iface any) {
+	selStr, _ := sel.(string)
+	p.traceLocator(selStr, countMatches(result), start)
+
+	defer p.traceIndex(cfg, &iface)
+
 	index, existed := cfgIndex(cfg)
 	if index == nil {
 		if !existed {
@@ -330,19 +376,47 @@ func (p *JSONParser) parseDomNodes(
 	}
 }
 
+// hasValue returns true if any result located by cfg is not empty
+func (p *JSONParser) hasValue(key string, cfg map[string]any, result gjson.Result) bool {
+	elems, _ := p.getAllElems(key, cfg, result)
+
+	var arr []gjson.Result
+
+	switch dom := elems.(type) {
+	case gjson.Result:
+		arr = append(arr, dom)
+	case []gjson.Result:
+		arr = dom
+	}
+
+	for _, res := range arr {
+		if res.Exists() && res.String() != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *JSONParser) getNodesAttrs(
 	key string,
 	cfg map[string]any,
 	selection gjson.Result,
 	data map[string]any,
 ) {
+	p.beginLeaf()
+
 	// first of all, check if _raw is set or not.
 	if val := mustCfgRaw(cfg); val != nil && val != "" {
 		data[key] = p.convertToType(val, cfg)
+		p.commitLeaf(data[key])
+
 		return
 	}
 
 	elems, complexSel := p.getAllElems(key, cfg, selection)
+	locs := p.jsonLocations(cfg, elems)
+	defer func() { p.commitLeaf(data[key], locs...) }()
 
 	switch dom := elems.(type) {
 	case gjson.Result:
@@ -0,0 +1,182 @@
+package xparse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+	"golang.org/x/net/html"
+)
+
+// options of _label, check Label for more info
+const (
+	_labelText       = "text"
+	_labelRegex      = "regex"
+	_labelIgnoreCase = "ignore_case"
+)
+
+type labelMatcher struct {
+	text       string
+	ignoreCase bool
+	rgx        *regexp.Regexp
+}
+
+// newLabelMatcher returns the matcher of _label, the regex is compiled once and cached, check compileRegexes
+func (p *Parser) newLabelMatcher(key string, cfg any) *labelMatcher {
+	switch v := cfg.(type) {
+	case string:
+		return &labelMatcher{text: normalizeLabel(v)}
+	case map[string]any:
+		m := &labelMatcher{
+			text:       normalizeLabel(cast.ToString(v[_labelText])),
+			ignoreCase: cast.ToBool(v[_labelIgnoreCase]),
+		}
+
+		if cast.ToBool(v[_labelRegex]) {
+			expr := cast.ToString(v[_labelText])
+			if m.ignoreCase {
+				expr = "(?i)" + expr
+			}
+
+			m.rgx = p.compileRegex(Label, key, expr)
+		}
+
+		return m
+	default:
+		panic(xpretty.Redf("%s of %s must be string or map, but got (%T: %v)", Label, key, cfg, cfg))
+	}
+}
+
+// match checks text (already normalized) is the label
+func (m *labelMatcher) match(text string) bool {
+	switch {
+	case m.rgx != nil:
+		return m.rgx.MatchString(text)
+	case m.ignoreCase:
+		return strings.EqualFold(text, m.text)
+	default:
+		return text == m.text
+	}
+}
+
+// cutPrefix returns the value of text like "Salary: 100k"
+func (m *labelMatcher) cutPrefix(text string) (string, bool) {
+	if m.rgx != nil {
+		return "", false
+	}
+
+	label, value, found := strings.Cut(text, ":")
+	if !found || !m.match(normalizeLabel(label)) {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
+
+// getLabelValue returns the refined value of the label, check Label for more info
+func (p *HTMLParser) getLabelValue(key string, cfg map[string]any, selection *goquery.Selection) any {
+	scope := selection
+	if loc, ok := mustCfgLocator(cfg).(string); ok && loc != "" {
+		scope = selection.Find(loc)
+	}
+
+	value, tail, inText := p.findLabelValue(p.newLabelMatcher(key, cfg[Label]), scope)
+
+	var raw any
+
+	if inText {
+		raw = p.TrimSpace(tail, cfg)
+	} else {
+		raw = p.getRawAttr(cfg, value)
+	}
+
+	raw = p.stripChars(key, raw, cfg)
+	raw = p.refineAttr(key, raw, cfg, value)
+	raw = p.advancedPostRefineAttr(key, raw, cfg)
+
+	return p.convertToType(raw, cfg)
+}
+
+// findLabelValue returns the value node of the label, or the text after the label (with inText) and the node has the text,
+// an empty selection is returned if label is not found
+func (p *HTMLParser) findLabelValue(m *labelMatcher, scope *goquery.Selection) (value *goquery.Selection, tail string, inText bool) {
+	var labelElem *goquery.Selection
+
+	scope.Find("*").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if !m.match(normalizeLabel(sel.Text())) {
+			return true
+		}
+
+		// the deepest one is the label
+		if sel.Children().FilterFunction(func(_ int, child *goquery.Selection) bool {
+			return m.match(normalizeLabel(child.Text()))
+		}).Length() == 0 {
+			labelElem = sel
+			return false
+		}
+
+		return true
+	})
+
+	if labelElem != nil {
+		return p.labelValueOf(labelElem)
+	}
+
+	// the label and value are in the same node, like <li>Salary: 100k</li>
+	value = scope.Slice(0, 0)
+
+	scope.Find("*").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if sel.Children().Length() != 0 {
+			return true
+		}
+
+		if v, ok := m.cutPrefix(normalizeSpace(sel.Text())); ok {
+			value, tail, inText = sel, v, true
+		}
+
+		return !inText
+	})
+
+	return value, tail, inText
+}
+
+func (p *HTMLParser) labelValueOf(label *goquery.Selection) (*goquery.Selection, string, bool) {
+	switch goquery.NodeName(label) {
+	case "dt":
+		return label.NextAllFiltered("dd").First(), "", false
+	case "th", "td":
+		return label.NextAllFiltered("td").First(), "", false
+	case "label":
+		if id, ok := label.Attr("for"); ok {
+			if root, _ := p.Root.(*goquery.Selection); root != nil {
+				return root.Find("#" + id).First(), "", false
+			}
+		}
+	}
+
+	// the text after label, like <p><b>Salary:</b> 100k</p>
+	next := label.Nodes[0].NextSibling
+	if next != nil && next.Type == html.TextNode && strings.TrimSpace(next.Data) != "" || label.Next().Length() == 0 {
+		var arr []string
+
+		for n := next; n != nil; n = n.NextSibling {
+			switch n.Type {
+			case html.TextNode:
+				arr = append(arr, n.Data)
+			case html.ElementNode:
+				arr = append(arr, goquery.NewDocumentFromNode(n).Text())
+			}
+		}
+
+		return label.Parent(), normalizeSpace(strings.Join(arr, " ")), true
+	}
+
+	return label.Next(), "", false
+}
+
+// normalizeLabel collapses spaces and removes the trailing colon
+func normalizeLabel(s string) string {
+	return strings.TrimSpace(strings.TrimRight(normalizeSpace(s), ":："))
+}
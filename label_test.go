@@ -0,0 +1,78 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body>
+<dl><dt>Location</dt><dd>Zurich</dd><dt>Salary:</dt><dd>100k</dd></dl>
+<table><tr><th>Job Type</th><td>Full-time</td></tr></table>
+<p><b>Posted:</b> 2 days ago</p>
+<ul><li>Level: Senior</li><li>Remote: true</li></ul>
+<form><label for="email">Email</label><input id="email" value="a@b.c"></form>
+<div class="box"><span>Team</span> <span>Core</span></div>
+</body></html>`
+
+	yml := `
+job:
+  location:
+    _label: Location
+  salary:
+    _label: salary
+  salary_ci:
+    _label: {text: salary, ignore_case: true}
+  type:
+    _label: {text: "^job\\s+type$", regex: true, ignore_case: true}
+  posted:
+    _label: Posted
+  level:
+    _label: Level
+  remote:
+    _label: Remote
+    _type: b
+  email:
+    _label: Email
+    _attr: value
+  team:
+    _locator: div.box
+    _label: Team
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(map[string]any{
+		"location":  "Zurich",
+		"salary":    "",
+		"salary_ci": "100k",
+		"type":      "Full-time",
+		"posted":    "2 days ago",
+		"level":     "Senior",
+		"remote":    true,
+		"email":     "a@b.c",
+		"team":      "Core",
+	}, p.ParsedData["job"])
+}
+
+func TestHTMLLabelLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	// invalid regex is reported when config is loaded
+	assert.Panics(func() {
+		NewHTMLParser([]byte(`<p>a</p>`), []byte("job:\n  level:\n    _label: {text: \"(\", regex: true}\n"))
+	})
+
+	// refiners of the text after label get the node has the text
+	p := NewHTMLParser([]byte(`<ul><li class="lv">Level: Senior</li></ul>`), []byte("job:\n  level:\n    _label: Level\n    _attr_refine: tagged\n"))
+	p.Refiners["tagged"] = func(args ...any) any {
+		return args[0].(string) + "@" + args[2].(*goquery.Selection).AttrOr("class", "")
+	}
+	p.DoParse()
+
+	assert.Equal(map[string]any{"level": "Senior@lv"}, p.ParsedData["job"])
+}
@@ -0,0 +1,189 @@
+package xparse
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+// _rawLocale is the parser-wide default of _locale
+const _rawLocale = "__raw.locale"
+
+// numLocale is how numbers are written in a locale
+type numLocale struct {
+	decimal rune
+	groups  string
+	// units are suffixes like K or Mio, keyed by lowercase
+	units map[string]float64
+}
+
+var (
+	_baseUnits = map[string]float64{"k": 1e3, "m": 1e6, "b": 1e9, "bn": 1e9, "mm": 1e6}
+
+	_spaceGroups = " \u00a0\u202f\u2009"
+
+	_deUnits  = withUnits(_baseUnits, map[string]float64{"tsd": 1e3, "mio": 1e6, "mrd": 1e9})
+	_cjkUnits = withUnits(_baseUnits, map[string]float64{"千": 1e3, "万": 1e4, "萬": 1e4, "亿": 1e8, "億": 1e8})
+
+	// _numLocales is keyed by lowercase locale or language, the locale is tried before its language
+	_numLocales = map[string]*numLocale{
+		"en": {decimal: '.', groups: ",", units: _baseUnits},
+		"en-in": {
+			decimal: '.', groups: ",",
+			units: withUnits(_baseUnits, map[string]float64{"l": 1e5, "lakh": 1e5, "lac": 1e5, "cr": 1e7, "crore": 1e7}),
+		},
+		"de":    {decimal: ',', groups: "." + _spaceGroups + "'", units: _deUnits},
+		"de-ch": {decimal: '.', groups: "'’" + _spaceGroups, units: _deUnits},
+		"fr":    {decimal: ',', groups: _spaceGroups + ".", units: withUnits(_baseUnits, map[string]float64{"md": 1e9})},
+		"es":    {decimal: ',', groups: "." + _spaceGroups, units: withUnits(_baseUnits, map[string]float64{"mil": 1e3})},
+		"it":    {decimal: ',', groups: "." + _spaceGroups, units: withUnits(_baseUnits, map[string]float64{"mila": 1e3, "mln": 1e6})},
+		"pt":    {decimal: ',', groups: "." + _spaceGroups, units: withUnits(_baseUnits, map[string]float64{"mil": 1e3})},
+		"nl":    {decimal: ',', groups: "." + _spaceGroups, units: withUnits(_baseUnits, map[string]float64{"mln": 1e6})},
+		"ru":    {decimal: ',', groups: _spaceGroups, units: withUnits(_baseUnits, map[string]float64{"тыс": 1e3, "млн": 1e6})},
+		"pl":    {decimal: ',', groups: _spaceGroups + ".", units: withUnits(_baseUnits, map[string]float64{"tys": 1e3, "mln": 1e6})},
+		"sv":    {decimal: ',', groups: _spaceGroups, units: _baseUnits},
+		"zh":    {decimal: '.', groups: ",", units: _cjkUnits},
+		"ja":    {decimal: '.', groups: ",", units: _cjkUnits},
+	}
+)
+
+// lookupNumLocale returns the locale like de-DE, or its language, en is returned if not found
+func lookupNumLocale(locale string) *numLocale {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if loc, ok := _numLocales[locale]; ok {
+		return loc
+	}
+
+	if loc, ok := _numLocales[localeLang(locale)]; ok {
+		return loc
+	}
+
+	return _numLocales["en"]
+}
+
+// parseLocaleNumber returns the first number of s and its unit (1 if no unit),
+// currency symbols, percent signs and other text around the number are ignored
+func parseLocaleNumber(s string, locale string) (value float64, unit float64, ok bool) {
+	loc := lookupNumLocale(locale)
+	runes := []rune(s)
+
+	start := -1
+
+	for i, r := range runes {
+		if unicode.IsDigit(r) {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 {
+		return 0, 1, false
+	}
+
+	var buf strings.Builder
+
+	if start > 0 && (runes[start-1] == '-' || runes[start-1] == '−') {
+		buf.WriteRune('-')
+	}
+
+	end := start
+
+	for ; end < len(runes); end++ {
+		r := runes[end]
+
+		switch {
+		case unicode.IsDigit(r):
+			buf.WriteRune(r)
+			continue
+		case r == loc.decimal || strings.ContainsRune(loc.groups, r):
+			// separators must be followed by a digit
+			if end+1 < len(runes) && unicode.IsDigit(runes[end+1]) {
+				if r == loc.decimal {
+					buf.WriteRune('.')
+				}
+
+				continue
+			}
+		}
+
+		break
+	}
+
+	value, err := strconv.ParseFloat(buf.String(), 64)
+	if err != nil {
+		return 0, 1, false
+	}
+
+	return value, numUnit(runes[end:], loc), true
+}
+
+// numUnit returns the multiplier of the unit word at the start of rest, like "K" or " Mio."
+func numUnit(rest []rune, loc *numLocale) float64 {
+	i := 0
+	for i < len(rest) && strings.ContainsRune(_spaceGroups, rest[i]) {
+		i++
+	}
+
+	// CJK units are not separated from the next word
+	if i < len(rest) && unicode.Is(unicode.Han, rest[i]) {
+		if v, ok := loc.units[string(rest[i])]; ok {
+			return v
+		}
+
+		return 1
+	}
+
+	j := i
+	for j < len(rest) && unicode.IsLetter(rest[j]) {
+		j++
+	}
+
+	if v, ok := loc.units[strings.ToLower(string(rest[i:j]))]; ok {
+		return v
+	}
+
+	return 1
+}
+
+func withUnits(base map[string]float64, units map[string]float64) map[string]float64 {
+	merged := make(map[string]float64, len(base)+len(units))
+
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range units {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// cfgLocale returns _locale of cfg, or the parser-wide __raw.locale
+func (p *Parser) cfgLocale(cfg map[string]any) string {
+	if v, ok := cfg[AttrLocale].(string); ok {
+		return v
+	}
+
+	return p.config.String(_rawLocale)
+}
+
+// toFloat converts raw to float, with the separators and units of _locale if set,
+// it's the same as without _locale if no number found
+func (p *Parser) toFloat(raw any, cfg map[string]any) float64 {
+	s, ok := raw.(string)
+	locale := p.cfgLocale(cfg)
+
+	if !ok || locale == "" {
+		return cast.ToFloat64(raw)
+	}
+
+	v, unit, ok := parseLocaleNumber(s, locale)
+	if !ok {
+		return cast.ToFloat64(raw)
+	}
+
+	return v * unit
+}
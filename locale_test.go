@@ -0,0 +1,101 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleNumber(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		raw    string
+		locale string
+		want   float64
+	}{
+		{"1.234,56 €", "de-DE", 1234.56},
+		{"1 234,56 €", "fr-FR", 1234.56},
+		{"CHF 1'234.50", "de-CH", 1234.5},
+		{"₹1,23,456", "en-IN", 123456},
+		{"$1,234.5", "en-US", 1234.5},
+		{"-12,5 %", "de", -12.5},
+	}
+
+	for _, tt := range tests {
+		v, err := CharToNum(tt.raw, Locale(tt.locale), Dft(0.0))
+		assert.Nil(err, tt.raw)
+		assert.Equal(tt.want, v, tt.raw)
+	}
+
+	v, err := CharToNum("1.234,56", Locale("de"))
+	assert.Nil(err)
+	assert.Equal(1234, v)
+
+	// failures are the same as without locale
+	for _, raw := range []string{"n/a", "..."} {
+		want, wantErr := CharToNum(raw)
+		v, err = CharToNum(raw, Locale("de"))
+		assert.Equal(want, v, raw)
+		assert.Equal(wantErr, err, raw)
+	}
+
+	_, err = CharToNum("n/a", Locale("de"))
+	assert.ErrorIs(err, ErrNoNumbers)
+
+	units := []struct {
+		raw    string
+		locale string
+		want   float64
+	}{
+		{"1,5 Mio. €", "de-DE", 1500000},
+		{"12 Tsd", "de", 12000},
+		{"2.5K views", "en", 2500},
+		{"3 Kč", "cs", 3},
+		{"1.5万", "zh-CN", 15000},
+		{"2 lakh", "en-IN", 200000},
+	}
+
+	for _, tt := range units {
+		v, ok := NumF64KMFromStr(tt.raw, Locale(tt.locale))
+		assert.True(ok, tt.raw)
+		assert.Equal(tt.want, v, tt.raw)
+
+		v2, err := CharToNum(tt.raw, Locale(tt.locale), Dft(0.0))
+		assert.Nil(err, tt.raw)
+		assert.Equal(tt.want, v2, tt.raw)
+	}
+
+	v, err = CharToNum("1,5 Mio", Locale("de"))
+	assert.Nil(err)
+	assert.Equal(1500000, v)
+}
+
+func TestLocaleType(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  locale: de-DE
+price:
+  _locator: .price
+  _type: f
+views:
+  _locator: .views
+  _locale: en
+  _type: i
+salary:
+  _locator: .salary
+  _type: salary
+none:
+  _locator: .none
+  _type: f
+`
+	p := NewHTMLParser([]byte(`<p class="price">1.234,56 €</p><p class="views">2.5K</p><p class="salary">3.000,50 € brutto/Monat</p><p class="none">n/a</p>`), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(1234.56, p.ParsedData["price"])
+	assert.Equal(2500, p.ParsedData["views"])
+	assert.Equal(3000.5, p.ParsedData["salary"].(map[string]any)["min"])
+	assert.Equal(0.0, p.ParsedData["none"])
+}
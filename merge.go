@@ -0,0 +1,204 @@
+package xparse
+
+import (
+	"fmt"
+
+	"github.com/spf13/cast"
+)
+
+// Page is a document of Merge, and the plan to parse it, so list and detail pages can have different configs
+type Page struct {
+	Document
+
+	Plan *BatchPlan
+}
+
+// MergeResult is the output of Merge
+type MergeResult struct {
+	// Items are the deduped list items, enriched by detail pages
+	Items []map[string]any
+	// Unmatched are the detail results whose join key is not found in list items
+	Unmatched []map[string]any
+	// Duplicates is the count of list items dropped as duplicated
+	Duplicates int
+}
+
+// default keys of Merge
+const (
+	_mergeListKey   = "jobs"
+	_mergeDetailKey = "job"
+	_mergeJoinKey   = "external_id"
+	_mergeRankKey   = "rank"
+)
+
+// idRenderer is the parser which prefixes ids with PID, check AppendPresetData
+type idRenderer interface {
+	renderIDField(field, value string) string
+}
+
+type MergeOpts struct {
+	listKey   string
+	detailKey string
+	joinKey   string
+	rankKey   string
+}
+
+type MergeOptFunc func(o *MergeOpts)
+
+func bindMergeOpts(opt *MergeOpts, opts ...MergeOptFunc) {
+	for _, f := range opts {
+		f(opt)
+	}
+}
+
+// WithMergeListKey sets the stub of items in list pages, default is jobs
+func WithMergeListKey(s string) MergeOptFunc {
+	return func(o *MergeOpts) {
+		o.listKey = s
+	}
+}
+
+// WithMergeDetailKey sets the stub of detail pages, default is job, the whole result is used if it's not found
+func WithMergeDetailKey(s string) MergeOptFunc {
+	return func(o *MergeOpts) {
+		o.detailKey = s
+	}
+}
+
+// WithMergeJoinKey sets the field to dedupe list items and to join detail pages on, default is external_id
+func WithMergeJoinKey(s string) MergeOptFunc {
+	return func(o *MergeOpts) {
+		o.joinKey = s
+	}
+}
+
+// WithMergeRankKey sets the field of rank to be recalculated, default is rank
+func WithMergeRankKey(s string) MergeOptFunc {
+	return func(o *MergeOpts) {
+		o.rankKey = s
+	}
+}
+
+// Merge parses list pages in order, and joins detail pages onto their items:
+//
+//   - items with the same join key are deduped, the first one is kept
+//   - ranks are renumbered in the deduped order, from the rank of the first item and by the step of the first two,
+//     so ranks are global without holes, and _rank.start/step are kept
+//   - non-empty fields of a detail page override the fields of its item,
+//     Document.ID is used as join key if the detail page has none, and is prefixed by PID like list items
+func Merge(lists, details []Page, opts ...MergeOptFunc) (*MergeResult, error) {
+	opt := &MergeOpts{
+		listKey:   _mergeListKey,
+		detailKey: _mergeDetailKey,
+		joinKey:   _mergeJoinKey,
+		rankKey:   _mergeRankKey,
+	}
+	bindMergeOpts(opt, opts...)
+
+	result := &MergeResult{}
+	indexOf := make(map[string]int)
+	// ranks of the first two kept items
+	var ranks []int
+
+	for i, page := range lists {
+		res := parseDocument(page.Plan, i, page.Document)
+		if res.Err != nil {
+			return nil, fmt.Errorf("list page %d: %w", i, res.Err)
+		}
+
+		items := stubItems(res.Data[opt.listKey])
+
+		for _, item := range items {
+			id := cast.ToString(item[opt.joinKey])
+			if id != "" {
+				if _, dup := indexOf[id]; dup {
+					result.Duplicates++
+					continue
+				}
+
+				indexOf[id] = len(result.Items)
+			}
+
+			if rank, ok := item[opt.rankKey]; ok {
+				if len(ranks) < 2 {
+					ranks = append(ranks, cast.ToInt(rank))
+				}
+
+				item[opt.rankKey] = mergedRank(ranks, len(result.Items))
+			}
+
+			result.Items = append(result.Items, item)
+		}
+	}
+
+	for i, page := range details {
+		res, parser := parseDocumentWith(page.Plan, i, page.Document)
+		if res.Err != nil {
+			return nil, fmt.Errorf("detail page %d: %w", i, res.Err)
+		}
+
+		detail, ok := res.Data[opt.detailKey].(map[string]any)
+		if !ok {
+			detail = res.Data
+		}
+
+		id := cast.ToString(detail[opt.joinKey])
+		if id == "" {
+			id = page.ID
+
+			if r, ok := parser.(idRenderer); ok {
+				id = r.renderIDField(opt.joinKey, id)
+			}
+		}
+
+		at, found := indexOf[id]
+		if !found {
+			result.Unmatched = append(result.Unmatched, detail)
+			continue
+		}
+
+		mergeDetail(result.Items[at], detail, opt)
+	}
+
+	return result, nil
+}
+
+// mergedRank returns the rank of the i-th kept item, by the first two ranks, the step is 1 if there's only one
+func mergedRank(ranks []int, i int) int {
+	step := 1
+	if len(ranks) > 1 && ranks[1] > ranks[0] {
+		step = ranks[1] - ranks[0]
+	}
+
+	return ranks[0] + i*step
+}
+
+// stubItems returns items of a list stub, which is []map[string]any, or []any of maps
+func stubItems(v any) []map[string]any {
+	switch v := v.(type) {
+	case []map[string]any:
+		return v
+	case []any:
+		items := make([]map[string]any, 0, len(v))
+
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+
+		return items
+	default:
+		return nil
+	}
+}
+
+func mergeDetail(item, detail map[string]any, opt *MergeOpts) {
+	for k, v := range detail {
+		if k == opt.joinKey || k == opt.rankKey || v == nil || v == "" {
+			continue
+		}
+
+		item[k] = v
+	}
+}
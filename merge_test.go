@@ -0,0 +1,104 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	listYml := `
+__raw:
+  site_field: ""
+  id_fields: {}
+jobs:
+  _locator: li
+  _index: ~
+  rank:
+    _attr_refine: bind_rank
+  external_id:
+    _attr: data-id
+  title:
+`
+	detailYml := `
+__raw:
+  site_field: ""
+  id_fields: {}
+job:
+  _locator: div.job
+  external_id:
+    _attr: data-id
+  salary:
+    _locator: .salary
+  title:
+    _locator: .missing
+`
+	listPlan, detailPlan := NewHTMLPlan([]byte(listYml)), NewHTMLPlan([]byte(detailYml))
+
+	lists := []Page{
+		{Document: Document{Raw: []byte(`<ul><li data-id="a">Go</li><li data-id="b">Rust</li></ul>`)}, Plan: listPlan},
+		{Document: Document{Raw: []byte(`<ul><li data-id="b">Rust</li><li data-id="c">Java</li></ul>`)}, Plan: listPlan},
+	}
+	details := []Page{
+		{Document: Document{Raw: []byte(`<div class="job" data-id="c"><p class="salary">100k</p></div>`)}, Plan: detailPlan},
+		{Document: Document{ID: "a", Raw: []byte(`<div class="job"><p class="salary">90k</p></div>`)}, Plan: detailPlan},
+		{Document: Document{Raw: []byte(`<div class="job" data-id="x"><p class="salary">1k</p></div>`)}, Plan: detailPlan},
+	}
+
+	res, err := Merge(lists, details)
+	assert.Nil(err)
+	assert.Equal(1, res.Duplicates)
+	assert.Equal([]map[string]any{
+		{"rank": 0, "external_id": "a", "title": "Go", "salary": "90k"},
+		{"rank": 1, "external_id": "b", "title": "Rust"},
+		{"rank": 2, "external_id": "c", "title": "Java", "salary": "100k"},
+	}, res.Items)
+	assert.Len(res.Unmatched, 1)
+	assert.Equal("x", res.Unmatched[0]["external_id"])
+
+	lists = append(lists, Page{Document: Document{Raw: []byte(`<ul><li>x</li></ul>`)}, Plan: NewHTMLPlan([]byte("jobs:\n  _locator: [\n"))})
+	_, err = Merge(lists, nil)
+	assert.ErrorIs(err, ErrParsePanic)
+}
+
+func TestMergeRankStepAndPID(t *testing.T) {
+	assert := assert.New(t)
+
+	listYml := []byte(`
+jobs:
+  _locator: li
+  _index: ~
+  _rank: {start: 1, step: 10}
+  rank:
+    _attr_refine: bind_rank
+  external_id:
+    _attr: data-id
+`)
+	detailYml := []byte("job:\n  _locator: div.job\n  salary:\n    _locator: .salary\n")
+
+	withPID := func(yml []byte) *BatchPlan {
+		return &BatchPlan{NewParser: func(raw []byte) IParser {
+			p := NewHTMLParser(raw, yml)
+			p.PID = "s1"
+
+			return p
+		}}
+	}
+
+	lists := []Page{
+		{Document: Document{Raw: []byte(`<ul><li data-id="a"></li><li data-id="b"></li></ul>`)}, Plan: withPID(listYml)},
+		{Document: Document{Raw: []byte(`<ul><li data-id="b"></li><li data-id="c"></li></ul>`)}, Plan: withPID(listYml)},
+	}
+	details := []Page{
+		{Document: Document{ID: "c", Raw: []byte(`<div class="job"><p class="salary">100k</p></div>`)}, Plan: withPID(detailYml)},
+	}
+
+	res, err := Merge(lists, details)
+	assert.Nil(err)
+	assert.Equal([]int{1, 11, 21}, ranksOf(res.Items))
+	assert.Equal("s1_c", res.Items[2]["external_id"])
+	assert.Equal("100k", res.Items[2]["salary"])
+	assert.Empty(res.Unmatched)
+}
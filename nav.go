@@ -0,0 +1,152 @@
+package xparse
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// steps of _nav, check Nav for more info
+const (
+	_navParent   = "parent"
+	_navNext     = "next"
+	_navPrev     = "prev"
+	_navClosest  = "closest"
+	_navSiblings = "siblings"
+	_navChildren = "children"
+	_navFind     = "find"
+	_navFirst    = "first"
+	_navLast     = "last"
+)
+
+// _navShortcuts are applied in this order when more than one are set, use _nav to control the order
+var _navShortcuts = []struct {
+	key  string
+	step string
+}{
+	{ExtractClosest, _navClosest},
+	{ExtractNext, _navNext},
+	{ExtractSiblings, _navSiblings},
+	{ExtractChildren, _navChildren},
+}
+
+// navigate moves elems by _nav and the _extract_xxx shortcuts,
+// _extract_prev and _extract_parent are only applied here when _index exists,
+// otherwise they're handled by handleNullIndexOnly as before
+func (p *HTMLParser) navigate(key string, cfg map[string]any, elems *goquery.Selection) *goquery.Selection {
+	if steps, ok := cfg[Nav]; ok {
+		arr, isList := steps.([]any)
+		if !isList {
+			panic(xpretty.Redf("%s of %s must be a list, but got (%T: %v)", Nav, key, steps, steps))
+		}
+
+		for _, step := range arr {
+			elems = p.navStep(key, step, elems)
+		}
+	}
+
+	for _, sc := range _navShortcuts {
+		if v, ok := cfg[sc.key]; ok {
+			elems = p.navStep(key, map[string]any{sc.step: v}, elems)
+		}
+	}
+
+	if _, existed := cfgIndex(cfg); !existed {
+		return elems
+	}
+
+	if v, ok := cfg[ExtractParent]; ok {
+		elems, _ = p.extractParent(key, v, elems).(*goquery.Selection)
+	}
+
+	if v, ok := cfg[ExtractPrevElem]; ok {
+		elems, _ = p.extractPrevNode(key, v, elems).(*goquery.Selection)
+	}
+
+	return elems
+}
+
+// navStep applies one step, step is a name like "next", or a map of name and arg like {next: dd}
+func (p *HTMLParser) navStep(key string, step any, elems *goquery.Selection) *goquery.Selection {
+	var (
+		name string
+		arg  any = true
+	)
+
+	switch v := step.(type) {
+	case string:
+		name = v
+	case map[string]any:
+		if len(v) != 1 {
+			panic(xpretty.Redf("step of %s must have only one key, but got %v", key, v))
+		}
+
+		for k, a := range v {
+			name, arg = k, a
+		}
+	default:
+		panic(xpretty.Redf("step of %s must be string or map, but got (%T: %v)", key, step, step))
+	}
+
+	// true means no filter
+	sel, filtered := arg.(string)
+
+	switch name {
+	case _navParent:
+		n := 1
+		if !filtered && arg != true {
+			n = cast.ToInt(arg)
+		}
+
+		for i := 0; i < n; i++ {
+			elems = elems.Parent()
+		}
+
+		return elems
+	case _navNext:
+		if !filtered {
+			return elems.Next()
+		}
+
+		return firstOfEach(elems, func(s *goquery.Selection) *goquery.Selection { return s.NextAllFiltered(sel) })
+	case _navPrev:
+		if !filtered {
+			return elems.Prev()
+		}
+
+		return firstOfEach(elems, func(s *goquery.Selection) *goquery.Selection { return s.PrevAllFiltered(sel) })
+	case _navClosest:
+		return elems.Closest(sel)
+	case _navSiblings:
+		if !filtered {
+			return elems.Siblings()
+		}
+
+		return elems.SiblingsFiltered(sel)
+	case _navChildren:
+		if !filtered {
+			return elems.Children()
+		}
+
+		return elems.ChildrenFiltered(sel)
+	case _navFind:
+		return elems.Find(sel)
+	case _navFirst:
+		return elems.First()
+	case _navLast:
+		return elems.Last()
+	default:
+		panic(xpretty.Redf("unknown step (%s) of %s", name, key))
+	}
+}
+
+// firstOfEach calls fn with each node of elems, and returns the union of the first node fn returned
+func firstOfEach(elems *goquery.Selection, fn func(*goquery.Selection) *goquery.Selection) *goquery.Selection {
+	result := elems.Slice(0, 0)
+
+	elems.Each(func(_ int, s *goquery.Selection) {
+		result = result.AddSelection(fn(s).First())
+	})
+
+	return result
+}
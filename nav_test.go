@@ -0,0 +1,70 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLNavigation(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body>
+<dl>
+  <dt>Location</dt><dd>Zurich</dd>
+  <dt>Salary</dt><!-- note --><dd>100k</dd>
+</dl>
+<table>
+  <tr><th>Type</th><td class="v">Full-time</td></tr>
+  <tr><th>Level</th><td class="v">Senior</td></tr>
+</table>
+<ul><li>a</li><li class="x">b</li><li>c</li></ul>
+</body></html>`
+
+	yml := `
+info:
+  salary:
+    _locator: dt:contains("Salary")
+    _extract_next: dd
+  location:
+    _locator: dt
+    _index: 0
+    _extract_next: true
+  level:
+    _locator: th:contains("Level")
+    _nav:
+      - closest: tr
+      - find: td.v
+  rows:
+    _locator: td.v
+    _extract_closest: tr
+    _index: ~
+    _extract_children: th
+  siblings:
+    _locator: li.x
+    _extract_siblings: true
+    _index: ~
+  prev:
+    _locator: li.x
+    _index: 0
+    _extract_prev: true
+  last:
+    _locator: ul
+    _nav: [children, last]
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(map[string]any{
+		"salary":   "100k",
+		"location": "Zurich",
+		"level":    "Senior",
+		"rows":     []any{"Type", "Level"},
+		"siblings": []any{"a", "c"},
+		"prev":     "a",
+		"last":     "c",
+	}, p.ParsedData["info"])
+
+	p = NewHTMLParser([]byte(rawHTML), []byte("info:\n  x:\n    _locator: dt\n    _nav: [up]\n"))
+	assert.Panics(p.DoParse)
+}
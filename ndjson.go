@@ -0,0 +1,206 @@
+package xparse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// _defaultMaxLineSize is the max size of a single line of NDJSON input
+	_defaultMaxLineSize = 16 * 1024 * 1024
+)
+
+var ErrInvalidJSONLine = errors.New("invalid json line")
+
+type NDJSONOpts struct {
+	offset      int64
+	maxLineSize int
+	skipInvalid bool
+	envelope    bool
+
+	checkpointEvery int
+	checkpoint      func(stats NDJSONStats)
+}
+
+type NDJSONOptFunc func(o *NDJSONOpts)
+
+func bindNDJSONOpts(opt *NDJSONOpts, opts ...NDJSONOptFunc) {
+	for _, f := range opts {
+		f(opt)
+	}
+}
+
+// WithNDJSONOffset resumes parsing from the byte offset returned by a previous run (NDJSONStats.Offset)
+func WithNDJSONOffset(offset int64) NDJSONOptFunc {
+	return func(o *NDJSONOpts) {
+		o.offset = offset
+	}
+}
+
+// WithMaxLineSize limits the memory used by a single line, lines longer than n are reported as bufio.ErrTooLong
+func WithMaxLineSize(n int) NDJSONOptFunc {
+	return func(o *NDJSONOpts) {
+		o.maxLineSize = n
+	}
+}
+
+// WithSkipInvalidLines logs and skips lines which cannot be parsed, instead of stopping at the first one
+func WithSkipInvalidLines(b bool) NDJSONOptFunc {
+	return func(o *NDJSONOpts) {
+		o.skipInvalid = b
+	}
+}
+
+// WithNDJSONEnvelope wraps each output line as {"line": n, "offset": n, "data": {...}}
+func WithNDJSONEnvelope(b bool) NDJSONOptFunc {
+	return func(o *NDJSONOpts) {
+		o.envelope = b
+	}
+}
+
+// WithNDJSONCheckpoint flushes the output and calls fn every n lines, fn receives the offset to resume from
+func WithNDJSONCheckpoint(n int, fn func(stats NDJSONStats)) NDJSONOptFunc {
+	return func(o *NDJSONOpts) {
+		o.checkpointEvery = n
+		o.checkpoint = fn
+	}
+}
+
+// NDJSONStats is the progress of ParseNDJSON
+type NDJSONStats struct {
+	// Lines is the count of lines read from the start offset, including empty and skipped lines
+	Lines int
+	// Written is the count of results written
+	Written int
+	// Skipped is the count of lines which cannot be parsed
+	Skipped int
+	// Offset is the byte offset of the input right after the last handled line,
+	// it can be passed to WithNDJSONOffset to resume
+	Offset int64
+}
+
+type ndjsonEnvelope struct {
+	Line   int            `json:"line"`
+	Offset int64          `json:"offset"`
+	Data   map[string]any `json:"data"`
+}
+
+// ParseNDJSON parses each line of r as an independent document with plan, and writes one JSON line per document to w.
+//
+// Only one line is held in memory at a time, so it is safe for huge JSON Lines files.
+// If r is an io.Seeker, WithNDJSONOffset seeks to the offset directly, otherwise the bytes before offset are discarded.
+func ParseNDJSON(r io.Reader, w io.Writer, plan *BatchPlan, opts ...NDJSONOptFunc) (NDJSONStats, error) {
+	opt := NDJSONOpts{maxLineSize: _defaultMaxLineSize}
+	bindNDJSONOpts(&opt, opts...)
+
+	stats := NDJSONStats{Offset: opt.offset}
+
+	if err := skipToOffset(r, opt.offset); err != nil {
+		return stats, err
+	}
+
+	writer := bufio.NewWriter(w)
+	defer writer.Flush()
+
+	var advance int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), opt.maxLineSize)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		adv, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			advance = adv
+		}
+
+		return adv, token, err
+	})
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		lineOffset := stats.Offset
+		stats.Lines++
+
+		if len(line) != 0 {
+			if err := writeNDJSONLine(writer, plan, line, lineOffset, &stats, opt); err != nil {
+				return stats, err
+			}
+		}
+
+		stats.Offset += int64(advance)
+
+		if opt.checkpoint != nil && opt.checkpointEvery > 0 && stats.Lines%opt.checkpointEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return stats, err
+			}
+
+			opt.checkpoint(stats)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("cannot read line at offset %d: %w", stats.Offset, err)
+	}
+
+	return stats, writer.Flush()
+}
+
+func writeNDJSONLine(w *bufio.Writer, plan *BatchPlan, line []byte, offset int64, stats *NDJSONStats, opt NDJSONOpts) error {
+	res := Result{Err: ErrInvalidJSONLine}
+	if gjson.ValidBytes(line) {
+		res = parseDocument(plan, stats.Lines-1, Document{Raw: line})
+	}
+
+	if res.Err != nil {
+		if !opt.skipInvalid {
+			return fmt.Errorf("cannot parse line %d at offset %d: %w", stats.Lines, offset, res.Err)
+		}
+
+		log.Warn().Err(res.Err).Int("line", stats.Lines).Int64("offset", offset).Msg("skip invalid line")
+		stats.Skipped++
+
+		return nil
+	}
+
+	var (
+		raw string
+		err error
+	)
+
+	if opt.envelope {
+		raw, err = Stringify(ndjsonEnvelope{Line: stats.Lines, Offset: offset, Data: res.Data})
+	} else {
+		raw, err = Stringify(res.Data)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.WriteString(raw + "\n"); err != nil {
+		return err
+	}
+
+	stats.Written++
+
+	return nil
+}
+
+func skipToOffset(r io.Reader, offset int64) error {
+	if offset <= 0 {
+		return nil
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r, offset)
+
+	return err
+}
@@ -0,0 +1,71 @@
+package xparse
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _ndjsonYaml = `
+job:
+  title: title
+  id: id
+`
+
+func TestParseNDJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	input := `{"id": 1, "title": "a"}
+
+{"id": 2, "title": "b"}
+{"id": 3, "title": "c"}`
+
+	var out bytes.Buffer
+
+	var checkpoints []int64
+
+	stats, err := ParseNDJSON(strings.NewReader(input), &out, NewJSONPlan([]byte(_ndjsonYaml)),
+		WithNDJSONCheckpoint(2, func(st NDJSONStats) { checkpoints = append(checkpoints, st.Offset) }))
+	assert.Nil(err)
+
+	want := `{"job":{"id":"1","title":"a"}}
+{"job":{"id":"2","title":"b"}}
+{"job":{"id":"3","title":"c"}}
+`
+	assert.Equal(want, out.String())
+	assert.Equal(NDJSONStats{Lines: 4, Written: 3, Offset: int64(len(input))}, stats)
+	assert.Equal([]int64{25, 72}, checkpoints)
+
+	// resume from the 1st checkpoint
+	out.Reset()
+	stats, err = ParseNDJSON(strings.NewReader(input), &out, NewJSONPlan([]byte(_ndjsonYaml)),
+		WithNDJSONOffset(checkpoints[0]), WithNDJSONEnvelope(true))
+	assert.Nil(err)
+
+	want = `{"line":1,"offset":25,"data":{"job":{"id":"2","title":"b"}}}
+{"line":2,"offset":49,"data":{"job":{"id":"3","title":"c"}}}
+`
+	assert.Equal(want, out.String())
+	assert.Equal(int64(len(input)), stats.Offset)
+}
+
+func TestParseNDJSONInvalidLine(t *testing.T) {
+	assert := assert.New(t)
+
+	input := "{\"id\": 1}\n{broken\n{\"id\": 3}\n"
+
+	var out bytes.Buffer
+
+	stats, err := ParseNDJSON(strings.NewReader(input), &out, NewJSONPlan([]byte(_ndjsonYaml)))
+	assert.True(errors.Is(err, ErrInvalidJSONLine))
+	assert.Equal(1, stats.Written)
+	assert.Equal(int64(10), stats.Offset)
+
+	out.Reset()
+	stats, err = ParseNDJSON(strings.NewReader(input), &out, NewJSONPlan([]byte(_ndjsonYaml)), WithSkipInvalidLines(true))
+	assert.Nil(err)
+	assert.Equal(NDJSONStats{Lines: 3, Written: 2, Skipped: 1, Offset: int64(len(input))}, stats)
+}
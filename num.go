@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cast"
@@ -25,8 +26,9 @@ const (
 var ErrNoNumbers = errors.New("no number found")
 
 type NumOpts struct {
-	chars string
-	dft   any
+	chars  string
+	dft    any
+	locale string
 }
 
 type NumOptFunc func(o *NumOpts)
@@ -45,6 +47,14 @@ func Dft(i any) NumOptFunc {
 	}
 }
 
+// Locale parses number with the separators and units of locale (like de-DE or en-IN),
+// the unit is applied, so "1,5 Mio" is 1500000, and Chars is ignored then, check parseLocaleNumber for more info
+func Locale(s string) NumOptFunc {
+	return func(o *NumOpts) {
+		o.locale = s
+	}
+}
+
 func bindOpts(opt *NumOpts, opts ...NumOptFunc) {
 	for _, f := range opts {
 		f(opt)
@@ -58,6 +68,13 @@ func CharToNum(rawStr string, opts ...NumOptFunc) (v any, e error) {
 	opt := NumOpts{chars: ".", dft: 1}
 	bindOpts(&opt, opts...)
 
+	// falls back to chars if no number found, so failures are the same as without locale
+	if opt.locale != "" {
+		if f, unit, ok := parseLocaleNumber(rawStr, opt.locale); ok {
+			return castNum(strconv.FormatFloat(f*unit, 'f', -1, 64), opt.dft)
+		}
+	}
+
 	a := "[0-9" + opt.chars + "]+"
 	re := regexp.MustCompile(a)
 	c := re.FindAllString(rawStr, -1)
@@ -71,7 +88,12 @@ func CharToNum(rawStr string, opts ...NumOptFunc) (v any, e error) {
 		return joinStr, ErrNoNumbers
 	}
 
-	switch opt.dft.(type) {
+	return castNum(joinStr, opt.dft)
+}
+
+// castNum converts joinStr to the type of dft
+func castNum(joinStr string, dft any) (any, error) {
+	switch dft.(type) {
 	case int:
 		v, e := cast.ToFloat64E(joinStr)
 		if e != nil {
@@ -106,6 +128,15 @@ func MustCharToNum(s string, opts ...NumOptFunc) (v any) {
 }
 
 func NumF64KMFromStr(str string, opts ...NumOptFunc) (i float64, b bool) {
+	opt := NumOpts{chars: ".", dft: 1}
+	bindOpts(&opt, opts...)
+
+	// the unit must follow the number with locale, like "1,5 Mio." or "3万"
+	if opt.locale != "" {
+		v, unit, ok := parseLocaleNumber(str, opt.locale)
+		return v * unit, ok
+	}
+
 	unit := 1.0
 
 	if strings.Contains(strings.ToUpper(str), "K") {
@@ -116,9 +147,6 @@ func NumF64KMFromStr(str string, opts ...NumOptFunc) (i float64, b bool) {
 		unit = 1000000.0
 	}
 
-	opt := NumOpts{chars: ".", dft: 1}
-	bindOpts(&opt, opts...)
-
 	if !strings.Contains(opt.chars, ".") {
 		opt.chars += "."
 	}
@@ -14,6 +14,26 @@ func cfgLocator(cfg map[string]any) (any, bool) {
 	return getConfig(cfg, Locator, LocatorAbbr)
 }
 
+// cfgFallback returns _fallback, or a list _locator with `_locator_mode: first`
+func cfgFallback(cfg map[string]any) ([]any, bool) {
+	if v, ok := cfg[Fallback]; ok {
+		arr, isList := v.([]any)
+		if !isList {
+			panic(xpretty.Redf("_fallback must be a list, but got (%T: %v)", v, v))
+		}
+
+		return arr, true
+	}
+
+	if cfg[LocatorMode] != LocatorModeFirst {
+		return nil, false
+	}
+
+	arr, ok := mustCfgLocator(cfg).([]any)
+
+	return arr, ok
+}
+
 // mustCfgRaw retrieves the raw configuration value from the config map.
 // It returns the value associated with the Raw key, ignoring any errors.
 // If the key doesn't exist, it returns nil.
@@ -0,0 +1,162 @@
+package xparse
+
+import (
+	"strings"
+)
+
+// keys in __raw to control how preset data is appended
+//
+//	__raw:
+//	  # stubs to be enriched, default is [jobs, job]
+//	  preset_targets: [products, reviews]
+//	  # key to save p.PID, default is site, set to "" to disable
+//	  site_field: site
+//	  # fields to be prefixed, default is {external_id: "{pid}_{value}"}
+//	  id_fields:
+//	    external_id: "{pid}_{value}"
+//	    sku: "{pid}-{value}"
+const (
+	_rawPresetTargets = "__raw.preset_targets"
+	_rawSiteField     = "__raw.site_field"
+	_rawIDFields      = "__raw.id_fields"
+)
+
+const (
+	_tplPID   = "{pid}"
+	_tplValue = "{value}"
+)
+
+var (
+	_defaultPresetTargets = []string{"jobs", "job"}
+	_defaultSiteField     = "site"
+	_defaultIDFields      = map[string]string{"external_id": _tplPID + "_" + _tplValue}
+)
+
+// StubHook post-processes every item of a stub, after preset data is appended
+type StubHook interface {
+	HookStub(stubKey string, item map[string]any)
+}
+
+// StubHookFunc is an adapter to allow the use of ordinary functions as StubHook
+type StubHookFunc func(stubKey string, item map[string]any)
+
+func (f StubHookFunc) HookStub(stubKey string, item map[string]any) {
+	f(stubKey, item)
+}
+
+// AddStubHook registers hook for stubKey, hooks are called in the order they are added,
+// and stubKey must be in preset_targets
+func (p *Parser) AddStubHook(stubKey string, hook StubHook) {
+	p.stubHooks[stubKey] = append(p.stubHooks[stubKey], hook)
+}
+
+// Deprecated: Use RefineStubsWithPreset instead.
+func (p *Parser) RefineJobsWithPreset() {
+	p.RefineStubsWithPreset()
+}
+
+// RefineStubsWithPreset appends preset data to every item of preset_targets, and then calls the stub hooks
+//
+//   - a stub of list ([]map[string]any), every item is enriched
+//   - a stub of map (map[string]any), the map itself is enriched
+func (p *Parser) RefineStubsWithPreset() {
+	for _, stubKey := range p.presetTargets() {
+		switch stub := p.GetParsedData(stubKey).(type) {
+		case []map[string]any:
+			for _, item := range stub {
+				p.refineStubItem(stubKey, item)
+			}
+		case map[string]any:
+			p.refineStubItem(stubKey, stub)
+		}
+	}
+}
+
+func (p *Parser) refineStubItem(stubKey string, item map[string]any) {
+	p.AppendPresetData(item)
+
+	for _, hook := range p.stubHooks[stubKey] {
+		hook.HookStub(stubKey, item)
+	}
+}
+
+// AppendPresetData appends preset data to data if key not existed,
+// then saves p.PID to site_field and prefixes id_fields with p.PID
+func (p *Parser) AppendPresetData(data map[string]any) {
+	pd := p.GetPresetData()
+	for k, v := range pd {
+		_, b := data[k]
+		if !b {
+			data[k] = v
+		}
+	}
+
+	if p.PID == "" {
+		return
+	}
+
+	// try add parser unique id to data
+	if field := p.siteField(); field != "" {
+		if _, found := data[field]; !found {
+			data[field] = p.PID
+		}
+	}
+
+	// try add p.PID to id fields
+	for field := range p.idFields() {
+		if s, _ := data[field].(string); s != "" {
+			data[field] = p.renderIDField(field, s)
+		}
+	}
+}
+
+// renderIDField renders value of field like AppendPresetData, value is returned as-is if PID is empty or field is not an id field
+func (p *Parser) renderIDField(field, value string) string {
+	tpl, ok := p.idFields()[field]
+	if p.PID == "" || !ok || value == "" {
+		return value
+	}
+
+	return RenderIDTemplate(tpl, p.PID, value)
+}
+
+// RenderIDTemplate replaces {pid} and {value} in tpl,
+// value is returned as-is if it's already rendered with the same pid, so it's safe to be called more than once
+func RenderIDTemplate(tpl, pid, value string) string {
+	tpl = strings.ReplaceAll(tpl, _tplPID, pid)
+
+	prefix, suffix, found := strings.Cut(tpl, _tplValue)
+	if !found {
+		return tpl
+	}
+
+	if len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix) {
+		return value
+	}
+
+	return prefix + value + suffix
+}
+
+func (p *Parser) presetTargets() []string {
+	if arr := p.config.Strings(_rawPresetTargets); len(arr) != 0 {
+		return arr
+	}
+
+	return _defaultPresetTargets
+}
+
+func (p *Parser) siteField() string {
+	if !p.config.Exists(_rawSiteField) {
+		return _defaultSiteField
+	}
+
+	return p.config.String(_rawSiteField)
+}
+
+func (p *Parser) idFields() map[string]string {
+	if !p.config.Exists(_rawIDFields) {
+		return _defaultIDFields
+	}
+
+	return p.config.StringMap(_rawIDFields)
+}
@@ -0,0 +1,80 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _presetJSON = `{
+  "products": [{"sku": "a1", "name": "pen"}, {"sku": "b2", "name": "ink"}],
+  "jobs": [{"id": "j1"}]
+}`
+
+func TestRefineStubsWithPresetDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: jobs
+  _index: ~
+  external_id: id
+`
+	p := NewJSONParser([]byte(_presetJSON), []byte(yml))
+	p.PID = "42"
+	p.BindPresetData(map[string]any{"country": "CH"})
+	p.DoParse()
+
+	want := []map[string]any{
+		{"external_id": "42_j1", "site": "42", "country": "CH"},
+	}
+	assert.Equal(want, p.ParsedData["jobs"])
+
+	// already prefixed
+	p.RefineStubsWithPreset()
+	assert.Equal(want, p.ParsedData["jobs"])
+}
+
+func TestRefineStubsWithPresetTargets(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  preset_targets: [products]
+  site_field: shop
+  id_fields:
+    sku: "{pid}-{value}-x"
+
+products:
+  _locator: products
+  _index: ~
+  sku: sku
+  name: name
+
+jobs:
+  _locator: jobs
+  _index: ~
+  external_id: id
+`
+	p := NewJSONParser([]byte(_presetJSON), []byte(yml))
+	p.PID = "7"
+	p.AddStubHook("products", StubHookFunc(func(stubKey string, item map[string]any) {
+		item["stub"] = stubKey
+	}))
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"sku": "7-a1-x", "name": "pen", "shop": "7", "stub": "products"},
+		{"sku": "7-b2-x", "name": "ink", "shop": "7", "stub": "products"},
+	}, p.ParsedData["products"])
+	assert.Equal([]map[string]any{{"external_id": "j1"}}, p.ParsedData["jobs"])
+}
+
+func TestRenderIDTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("1_abc", RenderIDTemplate("{pid}_{value}", "1", "abc"))
+	assert.Equal("1_abc", RenderIDTemplate("{pid}_{value}", "1", "1_abc"))
+	assert.Equal("abc@1", RenderIDTemplate("{value}@{pid}", "1", "abc"))
+	assert.Equal("1", RenderIDTemplate("{pid}", "1", "abc"))
+}
@@ -0,0 +1,420 @@
+package xparse
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/html"
+)
+
+const (
+	// _maxTagLookAhead is how many start tags are checked when aligning a parsed html node with its source tag,
+	// nodes implied by the html parser (like tbody) have no source tag
+	_maxTagLookAhead = 8
+)
+
+// NodeLocation is where a leaf value comes from
+type NodeLocation struct {
+	// Locator is the selector actually matched the node
+	Locator string `json:"locator,omitempty"`
+	// Alt is the index of Locator in a list _locator, -1 if _locator is not a list
+	Alt int `json:"alt"`
+	// Path is the CSS path of a html node, or the gjson path of a json value
+	Path string `json:"path,omitempty"`
+	// Offset is the byte offset in source, -1 if unknown
+	Offset int `json:"offset"`
+	// Line and Column are 1-based, 0 if unknown
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// FieldProvenance records how a leaf of ParsedData is produced
+type FieldProvenance struct {
+	// KeyPath is the key path in yaml config, like jobs.company.name
+	KeyPath  string         `json:"key_path"`
+	Nodes    []NodeLocation `json:"nodes,omitempty"`
+	Refiners []string       `json:"refiners,omitempty"`
+}
+
+type provenanceTracker struct {
+	// fields is a map of output path (like jobs.0.company.name) and its provenance
+	fields map[string]*FieldProvenance
+
+	// current is the leaf being parsed
+	current *FieldProvenance
+	// alternatives matched by list _locator of current leaf
+	htmlAlts map[*html.Node]NodeLocation
+
+	htmlOffsets map[*html.Node]int
+	lineStarts  []int
+}
+
+// ToggleProvenance enables or disables provenance tracking, it must be called before DoParse
+func (p *Parser) ToggleProvenance(b bool) {
+	if !b {
+		p.provenance = nil
+		return
+	}
+
+	p.provenance = &provenanceTracker{fields: make(map[string]*FieldProvenance)}
+}
+
+// Provenance returns a map of output path (like jobs.0.company.name) and how the value is produced,
+// nil is returned if provenance is not enabled
+func (p *Parser) Provenance() map[string]*FieldProvenance {
+	if p.provenance == nil {
+		return nil
+	}
+
+	return p.provenance.fields
+}
+
+func (p *Parser) pushOutputKey(key string) {
+	p.outputPath = append(p.outputPath, key)
+	p.keyPath = append(p.keyPath, key)
+}
+
+func (p *Parser) popOutputKey() {
+	p.outputPath = p.outputPath[:len(p.outputPath)-1]
+	p.keyPath = p.keyPath[:len(p.keyPath)-1]
+}
+
+func (p *Parser) pushOutputIndex(i int) {
+	p.outputPath = append(p.outputPath, strconv.Itoa(i))
+}
+
+func (p *Parser) popOutputIndex() {
+	p.outputPath = p.outputPath[:len(p.outputPath)-1]
+}
+
+func (p *Parser) beginLeaf() {
+	if p.provenance == nil {
+		return
+	}
+
+	p.provenance.current = &FieldProvenance{KeyPath: strings.Join(p.keyPath, ".")}
+	p.provenance.htmlAlts = make(map[*html.Node]NodeLocation)
+}
+
+func (p *Parser) commitLeaf(value any, nodes ...NodeLocation) {
+	p.traceValue(value)
+
+	if p.provenance == nil || p.provenance.current == nil {
+		return
+	}
+
+	p.provenance.current.Nodes = nodes
+	p.provenance.fields[strings.Join(p.outputPath, ".")] = p.provenance.current
+	p.provenance.current = nil
+}
+
+func (p *Parser) recordRefiner(name string) {
+	if p.provenance == nil || p.provenance.current == nil {
+		return
+	}
+
+	p.provenance.current.Refiners = append(p.provenance.current.Refiners, name)
+}
+
+func (p *Parser) recordHTMLAlt(locator string, alt int, elems any) {
+	if p.provenance == nil || p.provenance.htmlAlts == nil {
+		return
+	}
+
+	for _, node := range selectionNodes(elems) {
+		p.provenance.htmlAlts[node] = NodeLocation{Locator: locator, Alt: alt}
+	}
+}
+
+func (p *Parser) locate(offset int) (line, column int) {
+	if offset < 0 {
+		return 0, 0
+	}
+
+	if p.provenance.lineStarts == nil {
+		p.provenance.lineStarts = []int{0}
+
+		for i, c := range p.sourceData {
+			if c == '\n' {
+				p.provenance.lineStarts = append(p.provenance.lineStarts, i+1)
+			}
+		}
+	}
+
+	starts := p.provenance.lineStarts
+	i := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+
+	return i + 1, offset - starts[i] + 1
+}
+
+// htmlLocations returns locations of all nodes in elems, elems can be:
+// *goquery.Selection, []*goquery.Selection or map[string]*goquery.Selection
+func (p *HTMLParser) htmlLocations(locator any, elems any) []NodeLocation {
+	if p.provenance == nil {
+		return nil
+	}
+
+	if p.provenance.htmlOffsets == nil {
+		root, _ := p.Root.(*goquery.Selection)
+		p.provenance.htmlOffsets = htmlNodeOffsets(p.sourceData, root)
+	}
+
+	dft, _ := locator.(string)
+
+	var locs []NodeLocation
+
+	for _, node := range selectionNodes(elems) {
+		loc, ok := p.provenance.htmlAlts[node]
+		if !ok {
+			loc = NodeLocation{Locator: dft, Alt: -1}
+		}
+
+		loc.Path = CSSPath(node)
+		loc.Offset = -1
+
+		if offset, ok := p.provenance.htmlOffsets[node]; ok {
+			loc.Offset = offset
+			loc.Line, loc.Column = p.locate(offset)
+		}
+
+		locs = append(locs, loc)
+	}
+
+	return locs
+}
+
+func selectionNodes(elems any) []*html.Node {
+	var nodes []*html.Node
+
+	switch dom := elems.(type) {
+	case *goquery.Selection:
+		nodes = append(nodes, dom.Nodes...)
+	case []*goquery.Selection:
+		for _, sel := range dom {
+			nodes = append(nodes, sel.Nodes...)
+		}
+	case map[string]*goquery.Selection:
+		keys := make([]string, 0, len(dom))
+		for k := range dom {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if dom[k] != nil {
+				nodes = append(nodes, dom[k].Nodes...)
+			}
+		}
+	}
+
+	return nodes
+}
+
+// jsonLocation returns the location of result, alt is the index of locator in a list _locator
+func (p *JSONParser) jsonLocation(locator string, alt int, result gjson.Result) NodeLocation {
+	loc := NodeLocation{Locator: locator, Alt: alt, Offset: -1}
+
+	root, _ := p.Root.(gjson.Result)
+	if p.provenance == nil || result.Index <= 0 || !result.Exists() {
+		return loc
+	}
+
+	if path, ok := gjsonPathAt(root, result.Index); ok {
+		loc.Path = path
+	}
+
+	loc.Offset = result.Index
+	loc.Line, loc.Column = p.locate(result.Index)
+
+	return loc
+}
+
+// CSSPath returns a CSS selector which matches node only, like "html > body > div:nth-of-type(2) > h2"
+func CSSPath(node *html.Node) string {
+	var parts []string
+
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		index, total := 1, 1
+
+		if n.Parent != nil {
+			total = 0
+
+			for s := n.Parent.FirstChild; s != nil; s = s.NextSibling {
+				if s.Type != html.ElementNode || s.Data != n.Data {
+					continue
+				}
+
+				total++
+
+				if s == n {
+					index = total
+				}
+			}
+		}
+
+		part := n.Data
+		if total > 1 {
+			part = fmt.Sprintf("%s:nth-of-type(%d)", n.Data, index)
+		}
+
+		parts = append([]string{part}, parts...)
+	}
+
+	return strings.Join(parts, " > ")
+}
+
+// htmlNodeOffsets aligns element nodes of the parsed tree with start tags in raw html,
+// and returns the byte offset of each element node found
+func htmlNodeOffsets(raw []byte, root *goquery.Selection) map[*html.Node]int {
+	type startTag struct {
+		name   string
+		offset int
+	}
+
+	var tags []startTag
+
+	offset := 0
+	tokenizer := html.NewTokenizer(bytes.NewReader(raw))
+
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		size := len(tokenizer.Raw())
+
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			name, _ := tokenizer.TagName()
+			tags = append(tags, startTag{name: string(name), offset: offset})
+		}
+
+		offset += size
+	}
+
+	offsets := make(map[*html.Node]int)
+	cursor := 0
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for i := cursor; i < len(tags) && i < cursor+_maxTagLookAhead; i++ {
+				if tags[i].name == n.Data {
+					offsets[n] = tags[i].offset
+					cursor = i + 1
+
+					break
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	if root != nil {
+		for _, n := range root.Nodes {
+			walk(n)
+		}
+	}
+
+	return offsets
+}
+
+// gjsonPathAt returns the gjson path of the value which starts at index of node
+func gjsonPathAt(node gjson.Result, index int) (string, bool) {
+	if !node.IsObject() && !node.IsArray() {
+		return "", false
+	}
+
+	var (
+		path  string
+		found bool
+		i     int
+	)
+
+	node.ForEach(func(key, value gjson.Result) bool {
+		name := strconv.Itoa(i)
+		if node.IsObject() {
+			name = escapeGjsonKey(key.String())
+		}
+
+		i++
+
+		if index < value.Index || index >= value.Index+len(value.Raw) {
+			return true
+		}
+
+		if index == value.Index {
+			path, found = name, true
+			return false
+		}
+
+		if sub, ok := gjsonPathAt(value, index); ok {
+			path, found = name+"."+sub, true
+		}
+
+		return false
+	})
+
+	return path, found
+}
+
+func escapeGjsonKey(key string) string {
+	return strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`).Replace(key)
+}
+
+// jsonLocations returns locations of all results in elems, elems is the return value of JSONParser.getAllElems
+func (p *JSONParser) jsonLocations(cfg map[string]any, elems any) []NodeLocation {
+	if p.provenance == nil {
+		return nil
+	}
+
+	var locs []NodeLocation
+
+	switch locator := mustCfgLocator(cfg).(type) {
+	case []any:
+		// every alternative returns one result
+		results, _ := elems.([]gjson.Result)
+		for i, res := range results {
+			if i < len(locator) {
+				sel, _ := locator[i].(string)
+				locs = append(locs, p.jsonLocation(sel, i, res))
+			}
+		}
+	case map[string]any:
+		results, _ := elems.(map[string]gjson.Result)
+
+		keys := make([]string, 0, len(results))
+		for k := range results {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			sel, _ := locator[k].(string)
+			locs = append(locs, p.jsonLocation(sel, -1, results[k]))
+		}
+	default:
+		sel, _ := locator.(string)
+
+		switch dom := elems.(type) {
+		case gjson.Result:
+			locs = append(locs, p.jsonLocation(sel, -1, dom))
+		case []gjson.Result:
+			for _, res := range dom {
+				locs = append(locs, p.jsonLocation(sel, -1, res))
+			}
+		}
+	}
+
+	return locs
+}
@@ -0,0 +1,91 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body>
+<div class="job"><h2>A</h2><a href="/a">link</a></div>
+<div class="job"><h3>B</h3></div>
+</body></html>`
+
+	yml := `
+jobs:
+  _locator: div.job
+  _index: ~
+  title:
+    _locator: [h2, h3]
+  url:
+    _locator: a
+    _attr: href
+    _attr_refine: enrich_url
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.ToggleProvenance(true)
+	p.DoParse()
+
+	prov := p.Provenance()
+
+	title := prov["jobs.1.title"]
+	assert.Equal("jobs.title", title.KeyPath)
+	assert.Equal([]NodeLocation{{
+		Locator: "h3", Alt: 1, Path: "html > body > div:nth-of-type(2) > h3",
+		Offset: 85, Line: 3, Column: 18,
+	}}, title.Nodes)
+	assert.Equal("<h3>", rawHTML[85:89])
+
+	url := prov["jobs.0.url"]
+	assert.Equal([]string{"enrich_url"}, url.Refiners)
+	assert.Equal("a", url.Nodes[0].Locator)
+	assert.Equal(-1, url.Nodes[0].Alt)
+	assert.Equal(`<a href="/a">`, rawHTML[url.Nodes[0].Offset:url.Nodes[0].Offset+13])
+
+	p = NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.DoParse()
+	assert.Nil(p.Provenance())
+}
+
+func TestJSONProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	rawJSON := `{
+  "data": {"jobs": [
+    {"title": "A", "salary": {"min": "10"}},
+    {"title": "B", "pay.max": "20"}
+  ]}
+}`
+
+	yml := `
+jobs:
+  _locator: data.jobs
+  _index: ~
+  title: title
+  salary:
+    _locator:
+      - salary.min
+      - pay\.max
+    _type: i
+`
+	p := NewJSONParser([]byte(rawJSON), []byte(yml))
+	p.ToggleProvenance(true)
+	p.DoParse()
+
+	prov := p.Provenance()
+
+	assert.Equal([]NodeLocation{{
+		Locator: "title", Alt: -1, Path: "data.jobs.1.title", Offset: 82, Line: 4, Column: 15,
+	}}, prov["jobs.1.title"].Nodes)
+	assert.Equal(`"B"`, rawJSON[82:85])
+
+	salary := prov["jobs.1.salary"]
+	assert.Equal("jobs.salary", salary.KeyPath)
+	assert.Equal([]string{"_type:i"}, salary.Refiners)
+	assert.Equal(NodeLocation{Locator: "salary.min", Alt: 0, Offset: -1}, salary.Nodes[0])
+	assert.Equal(NodeLocation{Locator: `pay\.max`, Alt: 1, Path: `data.jobs.1.pay\.max`, Offset: 98, Line: 4, Column: 31}, salary.Nodes[1])
+	assert.Equal(`"20"`, rawJSON[98:102])
+}
@@ -0,0 +1,56 @@
+package xparse
+
+import (
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// options of _rank, check Rank for more info
+const (
+	_rankStart   = "start"
+	_rankStep    = "step"
+	_rankPerPage = "per_page"
+
+	// _rawRankStart is the default of WithRankBase
+	_rawRankStart = "__raw.rank_start"
+)
+
+// BindRankBase sets the rank of the first item, default is __raw.rank_start or 0
+func (p *Parser) BindRankBase(n int) {
+	p.rankBase = n
+}
+
+// BindPage sets the current page number (starts from 1), which is used with _rank.per_page
+func (p *Parser) BindPage(n int) {
+	p.page = n
+}
+
+// globalRank converts the rank in current stub to the rank across pages:
+//
+//	start + ((page - 1) * per_page + local) * step
+func (p *Parser) globalRank(cfg map[string]any, local int) int {
+	start, step, perPage := p.rankBase, 1, 0
+
+	switch opt := cfg[Rank].(type) {
+	case nil:
+	case map[string]any:
+		if v, ok := opt[_rankStart]; ok {
+			start = cast.ToInt(v)
+		}
+
+		if v, ok := opt[_rankStep]; ok {
+			step = cast.ToInt(v)
+		}
+
+		perPage = cast.ToInt(opt[_rankPerPage])
+	default:
+		panic(xpretty.Redf("%s must be a map, but got (%T: %v)", Rank, opt, opt))
+	}
+
+	offset := 0
+	if perPage > 0 && p.page > 1 {
+		offset = (p.page - 1) * perPage
+	}
+
+	return start + (offset+local)*step
+}
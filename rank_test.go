@@ -0,0 +1,65 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _rankHTML = `<ul><li>a</li><li>b</li><li>c</li></ul>`
+
+func ranksOf(data any) []int {
+	var ranks []int
+	for _, item := range data.([]map[string]any) {
+		ranks = append(ranks, item["rank"].(int))
+	}
+
+	return ranks
+}
+
+func TestRankBase(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: li
+  _index: ~
+  rank:
+    _attr_refine: bind_rank
+`
+	p := NewHTMLParser([]byte(_rankHTML), []byte(yml))
+	DoParse(p, WithRankBase(3))
+	assert.Equal([]int{3, 4, 5}, ranksOf(p.ParsedData["jobs"]))
+
+	p = NewHTMLParser([]byte(_rankHTML), []byte("__raw:\n  rank_start: 1\n"+yml))
+	p.DoParse()
+	assert.Equal([]int{1, 2, 3}, ranksOf(p.ParsedData["jobs"]))
+}
+
+func TestRankBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: li
+  _index: ~
+  _rank:
+    start: 1
+    step: 10
+    per_page: 3
+  rank:
+    _attr_refine: bind_rank
+`
+	p := NewHTMLParser([]byte(_rankHTML), []byte(yml))
+	DoParse(p, WithPage(2))
+	assert.Equal([]int{31, 41, 51}, ranksOf(p.ParsedData["jobs"]))
+
+	// page of preset data is a plain field, and not used as the page number
+	p = NewHTMLParser([]byte(_rankHTML), []byte(yml))
+	DoParse(p, WithPresetData(map[string]any{"page": 2}))
+	assert.Equal([]int{1, 11, 21}, ranksOf(p.ParsedData["jobs"]))
+
+	p = NewHTMLParser([]byte(_rankHTML), []byte(yml))
+	p.DoParse()
+	assert.Equal([]int{1, 11, 21}, ranksOf(p.ParsedData["jobs"]))
+}
@@ -0,0 +1,337 @@
+package xparse
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cast"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// _minRecords is the least count of similar siblings to be taken as a list
+	_minRecords = 3
+	// _minSimilarity is the least structural similarity of siblings to be taken as a list
+	_minSimilarity = 0.5
+	// _minFieldCoverage is the least ratio of records to have a field
+	_minFieldCoverage = 0.5
+)
+
+// tags which never be a record
+var _nonRecordTags = map[string]bool{
+	"html": true, "head": true, "body": true, "script": true, "style": true, "noscript": true,
+	"link": true, "meta": true, "br": true, "hr": true, "option": true, "template": true,
+}
+
+// RecordCandidate is a list of repeated sibling subtrees with similar structure
+type RecordCandidate struct {
+	// Container is the locator of each record
+	Container SelectorCandidate
+	// Count is how many records are found
+	Count int
+	// Similarity is the structural similarity of records, from 0 to 1
+	Similarity float64
+	// Fields are the child fields shared by most records, in document order
+	Fields []FieldCandidate
+	Score  float64
+}
+
+// FieldCandidate is a child field of RecordCandidate
+type FieldCandidate struct {
+	// Name is guessed from class or tag, like title/url/image
+	Name    string
+	Locator string
+	// Attr is href for links, src for images, empty for text
+	Attr string
+	// Coverage is the ratio of records which have the field
+	Coverage float64
+	// Sample is the value in the first record which has the field
+	Sample string
+}
+
+// DetectRecords finds repeated sibling subtrees with similar structure, like job cards of a list page,
+// candidates are ranked by score (count, similarity and fields), the first one is the most likely list
+//
+//	recs := DetectRecords(doc.Selection)
+//	yml := recs[0].Yaml("jobs")
+func DetectRecords(doc *goquery.Selection) []RecordCandidate {
+	var arr []RecordCandidate
+
+	seen := make(map[string]bool)
+
+	doc.Find("*").AddSelection(doc).Each(func(_ int, sel *goquery.Selection) {
+		for _, group := range similarChildren(sel.Nodes[0]) {
+			rec, ok := newRecordCandidate(doc, group)
+			if !ok || seen[rec.Container.Locator] {
+				continue
+			}
+
+			seen[rec.Container.Locator] = true
+			arr = append(arr, rec)
+		}
+	})
+
+	sort.SliceStable(arr, func(i, j int) bool {
+		return arr[i].Score > arr[j].Score
+	})
+
+	return arr
+}
+
+// Yaml returns a skeleton config for HTMLParser, with the records as a list stub of rootKey
+func (c *RecordCandidate) Yaml(rootKey string) []byte {
+	stub := &yaml.Node{Kind: yaml.MappingNode}
+	appendYamlPair(stub, Locator, yamlScalar(c.Container.Locator))
+	appendYamlPair(stub, Index, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "~"})
+
+	for _, field := range c.Fields {
+		node := &yaml.Node{Kind: yaml.MappingNode}
+
+		locator := yamlScalar(field.Locator)
+		locator.LineComment = "e.g. " + field.Sample
+		appendYamlPair(node, Locator, locator)
+
+		if field.Attr != "" {
+			appendYamlPair(node, Attr, yamlScalar(field.Attr))
+		}
+
+		appendYamlPair(stub, field.Name, node)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	appendYamlPair(root, rootKey, stub)
+
+	return encodeYaml(root)
+}
+
+// similarChildren groups element children of node by tag and stable classes,
+// groups with less than _minRecords members are dropped
+func similarChildren(node *html.Node) [][]*html.Node {
+	groups := make(map[string][]*html.Node)
+
+	var keys []string
+
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || _nonRecordTags[c.Data] {
+			continue
+		}
+
+		sig := nodeStep(c)
+		if _, ok := groups[sig]; !ok {
+			keys = append(keys, sig)
+		}
+
+		groups[sig] = append(groups[sig], c)
+	}
+
+	var arr [][]*html.Node
+
+	for _, k := range keys {
+		if len(groups[k]) >= _minRecords {
+			arr = append(arr, groups[k])
+		}
+	}
+
+	return arr
+}
+
+// recordLeaf is a node with a value in a record, path is the selector relative to the record
+type recordLeaf struct {
+	path  string
+	node  *html.Node
+	attr  string
+	value string
+}
+
+func newRecordCandidate(root *goquery.Selection, records []*html.Node) (RecordCandidate, bool) {
+	leaves := make([][]recordLeaf, len(records))
+	freq := make(map[string]int)
+
+	var order []string
+
+	for i, rec := range records {
+		leaves[i] = recordLeaves(rec)
+
+		for _, leaf := range leaves[i] {
+			key := leaf.path + "@" + leaf.attr
+			if freq[key] == 0 {
+				order = append(order, key)
+			}
+
+			freq[key]++
+		}
+	}
+
+	common := make(map[string]bool)
+
+	for key, n := range freq {
+		if float64(n)/float64(len(records)) >= _minFieldCoverage {
+			common[key] = true
+		}
+	}
+
+	if len(common) == 0 {
+		return RecordCandidate{}, false
+	}
+
+	similarity := 0.0
+
+	for _, arr := range leaves {
+		shared, own := 0, 0
+
+		for _, leaf := range arr {
+			if common[leaf.path+"@"+leaf.attr] {
+				shared++
+			} else {
+				own++
+			}
+		}
+
+		// jaccard of the record's leaves and the common leaves
+		similarity += float64(shared) / float64(len(common)+own)
+	}
+
+	similarity /= float64(len(records))
+	if similarity < _minSimilarity {
+		return RecordCandidate{}, false
+	}
+
+	cand := RecordCandidate{
+		Container:  bestContainerCandidate(root, records),
+		Count:      len(records),
+		Similarity: similarity,
+	}
+
+	names := make(map[string]int)
+
+	for _, key := range order {
+		if !common[key] {
+			continue
+		}
+
+		field := FieldCandidate{Coverage: float64(freq[key]) / float64(len(records))}
+
+		for _, arr := range leaves {
+			for _, leaf := range arr {
+				if leaf.path+"@"+leaf.attr == key && field.Locator == "" {
+					field.Locator, field.Attr, field.Sample = leaf.path, leaf.attr, leaf.value
+					field.Name = uniqueFieldName(names, guessFieldName(leaf))
+				}
+			}
+		}
+
+		cand.Fields = append(cand.Fields, field)
+	}
+
+	cand.Score = float64(cand.Count) * cand.Similarity * float64(1+len(cand.Fields))
+
+	return cand, true
+}
+
+// recordLeaves returns the nodes with own text, links and images of rec, only the first node of each path is kept,
+// paths are relative to rec, like ":scope > div.meta > span"
+func recordLeaves(rec *html.Node) []recordLeaf {
+	var arr []recordLeaf
+
+	seen := make(map[string]bool)
+	add := func(leaf recordLeaf) {
+		key := leaf.path + "@" + leaf.attr
+		if leaf.value == "" || seen[key] {
+			return
+		}
+
+		seen[key] = true
+		arr = append(arr, leaf)
+	}
+
+	var walk func(n *html.Node, path []string)
+	walk = func(n *html.Node, path []string) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || _nonRecordTags[c.Data] {
+				continue
+			}
+
+			sub := append(append([]string{}, path...), recordStep(c))
+			sel := strings.Join(append([]string{_scopeLocator}, sub...), " > ")
+
+			add(recordLeaf{path: sel, node: c, value: ownText(c)})
+
+			switch c.Data {
+			case "a":
+				add(recordLeaf{path: sel, node: c, attr: "href", value: attrValue(c, "href")})
+			case "img":
+				add(recordLeaf{path: sel, node: c, attr: "src", value: attrValue(c, "src")})
+			}
+
+			walk(c, sub)
+		}
+	}
+
+	walk(rec, nil)
+
+	return arr
+}
+
+// nodeStep returns tag with stable classes of node, like div.meta
+func nodeStep(node *html.Node) string {
+	if classes := stableClasses(node); len(classes) != 0 {
+		return node.Data + "." + strings.Join(classes, ".")
+	}
+
+	return node.Data
+}
+
+// recordStep is nodeStep with stable attrs, so siblings differ only by attrs are kept as different fields
+func recordStep(node *html.Node) string {
+	return nodeStep(node) + strings.Join(stableAttrs(node), "")
+}
+
+// ownText returns the text of node, if node has a non-blank text child
+func ownText(node *html.Node) string {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode && strings.TrimSpace(c.Data) != "" {
+			return normalizeSpace(goquery.NewDocumentFromNode(node).Text())
+		}
+	}
+
+	return ""
+}
+
+func guessFieldName(leaf recordLeaf) string {
+	switch {
+	case leaf.attr == "href":
+		return "url"
+	case leaf.attr == "src":
+		return "image"
+	}
+
+	if v := attrValue(leaf.node, "itemprop"); v != "" {
+		return strings.ReplaceAll(v, "-", "_")
+	}
+
+	if classes := stableClasses(leaf.node); len(classes) != 0 {
+		return strings.ReplaceAll(classes[0], "-", "_")
+	}
+
+	switch leaf.node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return "title"
+	case "a":
+		return "link"
+	case "time":
+		return "date"
+	}
+
+	return leaf.node.Data
+}
+
+func uniqueFieldName(names map[string]int, name string) string {
+	names[name]++
+	if n := names[name]; n > 1 {
+		return name + "_" + cast.ToString(n)
+	}
+
+	return name
+}
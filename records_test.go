@@ -0,0 +1,53 @@
+package xparse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectRecords(t *testing.T) {
+	assert := assert.New(t)
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader([]byte(_suggestHTML)))
+	assert.Nil(err)
+
+	recs := DetectRecords(doc.Selection)
+	assert.NotEmpty(recs)
+
+	rec := recs[0]
+	assert.Equal("li.job", rec.Container.Locator)
+	assert.Equal(3, rec.Count)
+	assert.Equal([]FieldCandidate{
+		{Name: "title", Locator: ":scope > h2.title", Coverage: 1, Sample: "Go Developer"},
+		{Name: "span", Locator: ":scope > div.meta > span", Coverage: 1, Sample: "Zurich"},
+		{Name: "salary", Locator: `:scope > div.meta > span[itemprop="salary"]`, Coverage: 2.0 / 3, Sample: "100k"},
+		{Name: "apply", Locator: ":scope > a.apply", Coverage: 1, Sample: "Apply"},
+		{Name: "url", Locator: ":scope > a.apply", Attr: "href", Coverage: 1, Sample: "/jobs/1"},
+	}, rec.Fields)
+
+	p := NewHTMLParser([]byte(_suggestHTML), rec.Yaml("jobs"))
+	p.DoParse()
+
+	jobs, _ := p.ParsedData["jobs"].([]map[string]any)
+	assert.Len(jobs, 3)
+	assert.Equal("100k", jobs[0]["salary"])
+	assert.Equal(map[string]any{"title": "Java Developer", "span": "Bern", "salary": "", "apply": "Apply", "url": "/jobs/3"}, jobs[2])
+
+	// :scope locators only match the children of the record
+	nested := NewHTMLParser([]byte(`<ul><li><span>a</span><div><span>b</span></div></li></ul>`),
+		[]byte(`
+item:
+  _locator: li
+  direct:
+    _locator: ":scope > span"
+    _index: ~
+  deep:
+    _locator: span
+    _index: ~
+`))
+	nested.DoParse()
+	assert.Equal(map[string]any{"direct": []any{"a"}, "deep": []any{"a", "b"}}, nested.ParsedData["item"])
+}
@@ -0,0 +1,172 @@
+package xparse
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// options of the map form of _attr_regex, check AttrRegex for more info
+const (
+	_regexPattern = "pattern"
+	_regexGroup   = "group"
+	_regexAll     = "all"
+	_regexReplace = "replace"
+)
+
+type attrRegex struct {
+	rgx     *regexp.Regexp
+	group   int
+	all     bool
+	replace *string
+}
+
+// compileRegexes compiles all _attr_regex, _when.matches and _label regex of config,
+// so invalid patterns are reported when config is loaded
+func (p *Parser) compileRegexes() {
+	p.regexes = make(map[string]*regexp.Regexp)
+	p.attrRegexes = make(map[string]*attrRegex)
+
+	var walk func(key string, v any)
+
+	walk = func(key string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if rgx, ok := v[AttrRegex]; ok {
+				p.attrRegexOf(key, rgx)
+			}
+
+			if cond, ok := v[When]; ok {
+				p.compileWhen(key, cond)
+			}
+
+			if label, ok := v[Label]; ok {
+				p.newLabelMatcher(key, label)
+			}
+
+			for k, sub := range v {
+				walk(k, sub)
+			}
+		case []any:
+			for _, sub := range v {
+				walk(key, sub)
+			}
+		}
+	}
+
+	for k, v := range p.config.Data() {
+		walk(k, v)
+	}
+}
+
+// compileRegex returns the compiled pattern, which is cached by pattern, opt is the option of pattern for error message
+func (p *Parser) compileRegex(opt, key, pattern string) *regexp.Regexp {
+	if rgx, ok := p.regexes[pattern]; ok {
+		return rgx
+	}
+
+	rgx, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(xpretty.Redf("invalid %s of %s: %v", opt, key, err))
+	}
+
+	if p.regexes == nil {
+		p.regexes = make(map[string]*regexp.Regexp)
+	}
+
+	p.regexes[pattern] = rgx
+
+	return rgx
+}
+
+// attrRegexOf returns the parsed _attr_regex, which is cached by the printed cfg, so the map form is parsed once
+func (p *Parser) attrRegexOf(key string, cfg any) *attrRegex {
+	id := fmt.Sprintf("%#v", cfg)
+	if ar, ok := p.attrRegexes[id]; ok {
+		return ar
+	}
+
+	ar := p.newAttrRegex(key, cfg)
+
+	if p.attrRegexes == nil {
+		p.attrRegexes = make(map[string]*attrRegex)
+	}
+
+	p.attrRegexes[id] = ar
+
+	return ar
+}
+
+func (p *Parser) newAttrRegex(key string, cfg any) *attrRegex {
+	switch v := cfg.(type) {
+	case string:
+		return &attrRegex{rgx: p.compileRegex(AttrRegex, key, v)}
+	case map[string]any:
+		ar := &attrRegex{
+			rgx: p.compileRegex(AttrRegex, key, cast.ToString(v[_regexPattern])),
+			all: cast.ToBool(v[_regexAll]),
+		}
+
+		if s, ok := v[_regexReplace]; ok {
+			replace := cast.ToString(s)
+			ar.replace = &replace
+		}
+
+		switch g := v[_regexGroup].(type) {
+		case nil:
+		case string:
+			if ar.group = ar.rgx.SubexpIndex(g); ar.group < 0 {
+				panic(xpretty.Redf("unknown group (%s) in %s of %s", g, AttrRegex, key))
+			}
+		default:
+			ar.group = cast.ToInt(g)
+			if ar.group < 0 || ar.group > ar.rgx.NumSubexp() {
+				panic(xpretty.Redf("group (%d) out of range in %s of %s", ar.group, AttrRegex, key))
+			}
+		}
+
+		return ar
+	default:
+		panic(xpretty.Redf("%s of %s must be string or map, but got (%T: %v)", AttrRegex, key, cfg, cfg))
+	}
+}
+
+func (p *Parser) refineByRe(key string, raw any, cfg map[string]any) (refined any) {
+	rgx, ok := cfg[AttrRegex]
+	if !ok {
+		return raw
+	}
+
+	defer p.traceStep(AttrRegex, raw, time.Now(), &refined)
+
+	rawStr, _ := raw.(string)
+
+	return p.attrRegexOf(key, rgx).refine(rawStr)
+}
+
+// refine returns the replaced string if replace is set, or the group of all matches (as []any) if all,
+// or the group of the first match ("" if not matched)
+func (ar *attrRegex) refine(s string) any {
+	if ar.replace != nil {
+		return ar.rgx.ReplaceAllString(s, *ar.replace)
+	}
+
+	if ar.all {
+		matches := make([]any, 0)
+		for _, m := range ar.rgx.FindAllStringSubmatch(s, -1) {
+			matches = append(matches, m[ar.group])
+		}
+
+		return matches
+	}
+
+	m := ar.rgx.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+
+	return m[ar.group]
+}
@@ -0,0 +1,68 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/coghost/xpretty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrRegexMap(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+first:
+  _locator: p
+  _attr_regex: \d+
+group:
+  _locator: p
+  _attr_regex:
+    pattern: id=(\d+)
+    group: 1
+named:
+  _locator: p
+  _attr_regex:
+    pattern: id=(?P<id>\d+)
+    group: id
+all:
+  _locator: p
+  _attr_regex:
+    pattern: '#(\w+)'
+    group: 1
+    all: true
+replace:
+  _locator: p
+  _attr_regex:
+    pattern: id=(\d+)
+    replace: "job-$1"
+missing:
+  _locator: p
+  _attr_regex:
+    pattern: x=(\d+)
+    group: 1
+`
+	p := NewHTMLParser([]byte(`<p>2024 id=42 #go #rust</p>`), []byte(yml))
+	assert.Len(p.attrRegexes, 6)
+
+	p.DoParse()
+	assert.Len(p.attrRegexes, 6, "map form is parsed once when config is loaded")
+
+	assert.Equal("2024", p.ParsedData["first"])
+	assert.Equal("42", p.ParsedData["group"])
+	assert.Equal("42", p.ParsedData["named"])
+	assert.Equal([]any{"go", "rust"}, p.ParsedData["all"])
+	assert.Equal("2024 job-42 #go #rust", p.ParsedData["replace"])
+	assert.Equal("", p.ParsedData["missing"])
+}
+
+func TestAttrRegexInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Panics(func() {
+		NewHTMLParser([]byte(`<p></p>`), []byte("a:\n  b:\n    _locator: p\n    _attr_regex: '(\\d+'\n"))
+	})
+
+	assert.PanicsWithValue(xpretty.Redf("unknown group (%s) in %s of %s", "name", AttrRegex, "a"), func() {
+		NewJSONParser([]byte(`{}`), []byte("a:\n  _locator: p\n  _attr_regex: {pattern: '(\\d+)', group: name}\n"))
+	})
+}
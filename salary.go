@@ -0,0 +1,270 @@
+package xparse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// periods of Salary
+const (
+	PeriodHour  = "hour"
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// Salary is the structured salary parsed by ParseSalary
+type Salary struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	// Currency is ISO 4217 code like USD, "" if not found
+	Currency string `json:"currency"`
+	// Period is one of hour/day/week/month/year, "" if not found
+	Period string `json:"period"`
+	// Estimated is true if the salary is marked as estimated/approximate
+	Estimated bool `json:"estimated"`
+}
+
+// Map returns salary as a map, which is the value of _type: salary
+func (s *Salary) Map() map[string]any {
+	return map[string]any{
+		"min":       s.Min,
+		"max":       s.Max,
+		"currency":  s.Currency,
+		"period":    s.Period,
+		"estimated": s.Estimated,
+	}
+}
+
+var (
+	// _currencySymbols are checked in order, so the longer prefixed dollars are before $
+	_currencySymbols = []struct {
+		symbol   string
+		currency string
+	}{
+		{"US$", "USD"}, {"CA$", "CAD"}, {"C$", "CAD"}, {"AU$", "AUD"}, {"A$", "AUD"}, {"NZ$", "NZD"},
+		{"HK$", "HKD"}, {"S$", "SGD"}, {"R$", "BRL"},
+		{"$", "USD"}, {"£", "GBP"}, {"€", "EUR"}, {"₹", "INR"}, {"¥", "JPY"}, {"元", "CNY"}, {"₩", "KRW"},
+		{"zł", "PLN"}, {"₽", "RUB"}, {"₺", "TRY"},
+	}
+
+	// _dollarRegions are currencies of $ by the region of locale
+	_dollarRegions = map[string]string{
+		"CA": "CAD", "AU": "AUD", "NZ": "NZD", "SG": "SGD", "HK": "HKD", "MX": "MXN", "TW": "TWD",
+	}
+
+	_currencyCodeRegex = regexp.MustCompile(
+		`\b(USD|EUR|GBP|CHF|CAD|AUD|NZD|INR|JPY|CNY|RMB|SGD|HKD|SEK|NOK|DKK|PLN|CZK|ZAR|BRL|MXN|KRW|TWD)\b`)
+
+	_salaryNumberRegex = regexp.MustCompile(`(?i)(\d[\d.,']*)\s*(k|mio|mil|m|tsd|万|千)?`)
+
+	// _salaryRangeSeps are the separators between min and max, currency symbols around are ignored
+	_salaryRangeSeps = map[string]bool{
+		"-": true, "–": true, "—": true, "~": true, "to": true, "bis": true, "à": true, "a": true,
+	}
+
+	_salaryPeriods = []struct {
+		period string
+		regex  *regexp.Regexp
+	}{
+		{PeriodHour, regexp.MustCompile(`(?i)(hour|\bhr\b|/\s*h\b|stunde|\bstd\b|heure|時給|时薪|小时)`)},
+		{PeriodDay, regexp.MustCompile(`(?i)(\bday\b|daily|/\s*d\b|\btag\b|täglich|\bjour\b|日薪)`)},
+		{PeriodWeek, regexp.MustCompile(`(?i)(week|/\s*wk\b|woche|semaine)`)},
+		{PeriodMonth, regexp.MustCompile(`(?i)(month|/\s*mo\b|monat|\bmois\b|\bmes\b|月)`)},
+		{PeriodYear, regexp.MustCompile(`(?i)(year|annual|annum|\bp\.?a\.?(\s|$)|/\s*yr\b|jahr|par an|\baño\b|年)`)},
+	}
+
+	_estimatedRegex = regexp.MustCompile(`(?i)(estimat|\best\.|approx|\bca\.|circa|around|geschätzt|environ|~\s*\d)`)
+)
+
+var _salaryMultipliers = map[string]float64{
+	"k": 1e3, "tsd": 1e3, "千": 1e3, "万": 1e4, "m": 1e6, "mio": 1e6, "mil": 1e6,
+}
+
+// ParseSalary parses salary text like "$80K–$100K a year", "£15.50/hr" or "€3.000 - 4.000 brutto/Monat",
+// locale (like de-DE) decides the decimal separator and the currency of $, it's guessed if empty
+func ParseSalary(s string, locale string) (*Salary, error) {
+	s = strings.NewReplacer("\u00a0", " ", "\u202f", " ", "\u2009", " ").Replace(s)
+
+	salary := &Salary{
+		Currency:  salaryCurrency(s, locale),
+		Period:    salaryPeriod(s),
+		Estimated: _estimatedRegex.MatchString(s),
+	}
+
+	type amount struct {
+		value      float64
+		unit       float64
+		start, end int
+	}
+
+	var amounts []amount
+
+	for _, m := range _salaryNumberRegex.FindAllStringSubmatchIndex(s, -1) {
+		num := strings.TrimRight(s[m[2]:m[3]], ".,'")
+
+		v, ok := parseSalaryNumber(num, locale)
+		if !ok {
+			continue
+		}
+
+		unit := 1.0
+
+		if m[4] >= 0 {
+			suffix := strings.ToLower(s[m[4]:m[5]])
+			// like "5 months", the suffix is part of a word
+			if next := s[m[5]:]; !isCJK(suffix) && next != "" && isLetter(next) {
+				suffix = ""
+			}
+
+			if mul, found := _salaryMultipliers[suffix]; found {
+				unit = mul
+			}
+		}
+
+		amounts = append(amounts, amount{value: v, unit: unit, start: m[0], end: m[1]})
+	}
+
+	if len(amounts) == 0 {
+		return nil, ErrNoNumbers
+	}
+
+	lo, hi := amounts[0], amounts[0]
+	if len(amounts) > 1 && isSalaryRange(s[lo.end:amounts[1].start]) {
+		hi = amounts[1]
+	}
+
+	// like "80-100K", the unit of max is used by min too
+	if lo.unit == 1 && hi.unit != 1 && lo.value <= hi.value {
+		lo.unit = hi.unit
+	}
+
+	salary.Min, salary.Max = ToFixed(lo.value*lo.unit), ToFixed(hi.value*hi.unit)
+	if salary.Min > salary.Max {
+		salary.Min, salary.Max = salary.Max, salary.Min
+	}
+
+	return salary, nil
+}
+
+// toSalary is the hook of _type: salary, raw is returned if no salary found
+func (p *Parser) toSalary(raw any, locale string) any {
+	s, ok := raw.(string)
+	if !ok {
+		return raw
+	}
+
+	salary, err := ParseSalary(s, locale)
+	if err != nil {
+		return raw
+	}
+
+	return salary.Map()
+}
+
+// isSalaryRange checks text between two amounts is a range separator, like " - $" or " to "
+func isSalaryRange(between string) bool {
+	for _, c := range _currencySymbols {
+		between = strings.ReplaceAll(between, c.symbol, "")
+	}
+
+	between = _currencyCodeRegex.ReplaceAllString(between, "")
+
+	return _salaryRangeSeps[strings.ToLower(strings.TrimSpace(between))]
+}
+
+func salaryCurrency(s, locale string) string {
+	if m := _currencyCodeRegex.FindString(s); m != "" {
+		if m == "RMB" {
+			return "CNY"
+		}
+
+		return m
+	}
+
+	for _, c := range _currencySymbols {
+		if !strings.Contains(s, c.symbol) {
+			continue
+		}
+
+		if c.symbol == "$" {
+			if v, ok := _dollarRegions[localeRegion(locale)]; ok {
+				return v
+			}
+		}
+
+		return c.currency
+	}
+
+	return ""
+}
+
+func salaryPeriod(s string) string {
+	for _, p := range _salaryPeriods {
+		if p.regex.MatchString(s) {
+			return p.period
+		}
+	}
+
+	return ""
+}
+
+// parseSalaryNumber parses num like "3.000" or "15.50", if locale is empty,
+// a separator followed by exactly 3 digits at the end is taken as group separator
+func parseSalaryNumber(num, locale string) (float64, bool) {
+	decimal := string(lookupNumLocale(locale).decimal)
+
+	if locale == "" {
+		if i := strings.LastIndexAny(num, ".,"); i >= 0 {
+			decimal = num[i : i+1]
+			if len(num)-i-1 == 3 {
+				decimal = ""
+			}
+		}
+	}
+
+	var buf strings.Builder
+
+	for _, r := range num {
+		switch {
+		case r >= '0' && r <= '9':
+			buf.WriteRune(r)
+		case decimal != "" && string(r) == decimal:
+			buf.WriteRune('.')
+		}
+	}
+
+	v, err := cast.ToFloat64E(buf.String())
+
+	return v, err == nil
+}
+
+// localeLang returns the language of locale like de-DE or de_DE, lowercased
+func localeLang(locale string) string {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToLower(lang)
+}
+
+// localeRegion returns the region of locale like en-CA, uppercased
+func localeRegion(locale string) string {
+	_, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToUpper(region)
+}
+
+func isLetter(s string) bool {
+	r := []rune(s)[0]
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f && !isCJK(string(r))
+}
+
+func isCJK(s string) bool {
+	for _, r := range s {
+		if r >= 0x4e00 && r <= 0x9fff {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSalary(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		raw    string
+		locale string
+		want   Salary
+	}{
+		{"$80K–$100K a year", "", Salary{Min: 80000, Max: 100000, Currency: "USD", Period: PeriodYear}},
+		{"£15.50/hr", "", Salary{Min: 15.5, Max: 15.5, Currency: "GBP", Period: PeriodHour}},
+		{"€3.000 - 4.000 brutto/Monat", "", Salary{Min: 3000, Max: 4000, Currency: "EUR", Period: PeriodMonth}},
+		{"€3.000,50 brutto/Monat", "de-DE", Salary{Min: 3000.5, Max: 3000.5, Currency: "EUR", Period: PeriodMonth}},
+		{"Estimated $60-75K per year", "", Salary{Min: 60000, Max: 75000, Currency: "USD", Period: PeriodYear, Estimated: true}},
+		{"$25 an hour, 40 hours a week", "en-CA", Salary{Min: 25, Max: 25, Currency: "CAD", Period: PeriodHour}},
+		{"CHF 120'000 p.a.", "", Salary{Min: 120000, Max: 120000, Currency: "CHF", Period: PeriodYear}},
+		{"月薪 1.5万 - 2万 元", "", Salary{Min: 15000, Max: 20000, Currency: "CNY", Period: PeriodMonth}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSalary(tt.raw, tt.locale)
+		assert.Nil(err, tt.raw)
+		assert.Equal(tt.want, *got, tt.raw)
+	}
+
+	_, err := ParseSalary("Competitive", "")
+	assert.ErrorIs(err, ErrNoNumbers)
+}
+
+func TestSalaryType(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+salary:
+  _locator: span
+  _type: salary
+`
+	p := NewHTMLParser([]byte(`<span>$80K - $100K a year</span>`), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(map[string]any{
+		"min": 80000.0, "max": 100000.0, "currency": "USD", "period": PeriodYear, "estimated": false,
+	}, p.ParsedData["salary"])
+}
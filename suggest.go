@@ -0,0 +1,726 @@
+package xparse
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	_suggestRootKey    = "items"
+	_suggestCandidates = 3
+
+	// score of each kind of selector, the more stable, the higher
+	_stabilityAttr  = 3.0
+	_stabilityClass = 2.0
+	_stabilityTag   = 1.0
+	// penalty of a selector qualified by its parent
+	_penaltyNested = 0.5
+)
+
+var (
+	// class names generated by css-in-js or build tools, or state classes, which are not stable
+	_unstableClassRegex = regexp.MustCompile(`^(css|sc|jsx|emotion)-|_[a-zA-Z0-9]{5,}$|\d{3,}|[^a-zA-Z0-9_-]`)
+	_stateClasses       = map[string]bool{
+		"active": true, "selected": true, "current": true, "hover": true, "focus": true,
+		"odd": true, "even": true, "first": true, "last": true, "hidden": true, "show": true,
+	}
+	// attributes which are commonly used as stable hooks
+	_stableAttrRegex = regexp.MustCompile(`^(itemprop|itemtype|name|role|data-[a-z0-9_-]+)$`)
+)
+
+type SuggestOpts struct {
+	rootKey    string
+	candidates int
+}
+
+type SuggestOptFunc func(o *SuggestOpts)
+
+func bindSuggestOpts(opt *SuggestOpts, opts ...SuggestOptFunc) {
+	for _, f := range opts {
+		f(opt)
+	}
+}
+
+// WithSuggestRootKey sets the key of the repeating container in yaml, "items" by default
+func WithSuggestRootKey(key string) SuggestOptFunc {
+	return func(o *SuggestOpts) {
+		o.rootKey = key
+	}
+}
+
+// WithSuggestCandidates sets how many candidates of each field are kept in SuggestReport, 3 by default
+func WithSuggestCandidates(n int) SuggestOptFunc {
+	return func(o *SuggestOpts) {
+		o.candidates = n
+	}
+}
+
+// SelectorCandidate is a proposed locator
+type SelectorCandidate struct {
+	// Locator is empty if the value is in the container itself
+	Locator string
+	// Attr is the attribute holds the value, empty for text
+	Attr  string
+	Score float64
+	// Hits is how many examples are extracted as-is by the candidate
+	Hits int
+	// Matches is the count of matched nodes for container,
+	// or the count of containers which have the field for field
+	Matches int
+	// Multi is true if the field matches more than one node in every container which has it,
+	// so it's a list, and _index: ~ is set in yaml
+	Multi bool
+}
+
+// SuggestReport explains how the yaml is generated
+type SuggestReport struct {
+	// Container is nil if no repeating container found, and fields are located from document root
+	Container *SelectorCandidate
+	// Fields are candidates of each field, ranked by score, the first one is used in yaml
+	Fields map[string][]SelectorCandidate
+	// Unmatched are example values which are not found in html, or found but not extracted by any candidate
+	Unmatched map[string][]string
+}
+
+type exampleMatch struct {
+	value string
+	node  *html.Node
+	attr  string
+}
+
+// Suggest infers a yaml config for HTMLParser from a few example values of each field, like:
+//
+//	yml, report := Suggest(raw, map[string][]string{
+//		"title": {"Go Developer", "Rust Developer"},
+//		"url":   {"/jobs/1", "/jobs/2"},
+//	})
+//
+// when examples are found in more than one sibling elements, the sibling is used as the repeating container,
+// and fields are located relatively to it.
+// candidates are ranked by how many examples they extract and by stability: attributes (itemprop/data-*)
+// are preferred to class names, and class names to bare tags.
+func Suggest(rawHTML []byte, examples map[string][]string, opts ...SuggestOptFunc) ([]byte, *SuggestReport) {
+	opt := &SuggestOpts{rootKey: _suggestRootKey, candidates: _suggestCandidates}
+	bindSuggestOpts(opt, opts...)
+
+	report := &SuggestReport{
+		Fields:    make(map[string][]SelectorCandidate),
+		Unmatched: make(map[string][]string),
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(rawHTML))
+	PanicIfErr(err)
+
+	fields := make([]string, 0, len(examples))
+	for field := range examples {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	matches := make(map[string][]exampleMatch)
+
+	var allNodes []*html.Node
+
+	for _, field := range fields {
+		matches[field] = pickExampleMatches(doc, examples[field], func(value string) {
+			report.Unmatched[field] = append(report.Unmatched[field], value)
+		})
+
+		for _, m := range matches[field] {
+			allNodes = append(allNodes, m.node)
+		}
+	}
+
+	containers := doc.Selection.Nodes
+	scopeOf := func(*html.Node) *html.Node { return doc.Selection.Nodes[0] }
+
+	if listRoot := lowestCommonAncestor(allNodes); listRoot != nil {
+		if arr, owner := repeatingContainers(listRoot, allNodes); isRepeating(arr, owner, matches) {
+			cand := bestContainerCandidate(doc.Selection, arr)
+			report.Container = &cand
+			scopeOf = owner
+			// the nodes matched by container selector are used to check the coverage of fields
+			containers = doc.Find(cand.Locator).Nodes
+		}
+	}
+
+	for _, field := range fields {
+		if len(matches[field]) == 0 {
+			continue
+		}
+
+		cands := rankFieldCandidates(matches[field], scopeOf, containers, report.Container == nil)
+		if len(cands) == 0 {
+			for _, m := range matches[field] {
+				report.Unmatched[field] = append(report.Unmatched[field], m.value)
+			}
+
+			continue
+		}
+
+		if len(cands) > opt.candidates {
+			cands = cands[:opt.candidates]
+		}
+
+		report.Fields[field] = cands
+	}
+
+	return suggestYaml(fields, report, opt), report
+}
+
+// pickExampleMatches finds the node of each example value, when a value is found more than once,
+// the node whose tag path is shared by most examples is picked
+func pickExampleMatches(doc *goquery.Document, values []string, unmatched func(string)) []exampleMatch {
+	found := make([][]exampleMatch, 0, len(values))
+	freq := make(map[string]int)
+
+	for _, value := range values {
+		arr := findExampleNodes(doc, normalizeSpace(value))
+		if len(arr) == 0 {
+			unmatched(value)
+			continue
+		}
+
+		seen := make(map[string]bool)
+
+		for _, m := range arr {
+			sig := tagPath(m.node) + "@" + m.attr
+			if !seen[sig] {
+				seen[sig] = true
+				freq[sig]++
+			}
+		}
+
+		found = append(found, arr)
+	}
+
+	picked := make([]exampleMatch, 0, len(found))
+
+	for _, arr := range found {
+		best := arr[0]
+
+		for _, m := range arr[1:] {
+			if freq[tagPath(m.node)+"@"+m.attr] > freq[tagPath(best.node)+"@"+best.attr] {
+				best = m
+			}
+		}
+
+		picked = append(picked, best)
+	}
+
+	return picked
+}
+
+// findExampleNodes returns the deepest nodes whose text is value,
+// or nodes with an attribute of value if no text matched, nodes in <head> are ignored
+func findExampleNodes(doc *goquery.Document, value string) []exampleMatch {
+	var byText, byAttr []exampleMatch
+
+	doc.Find("body *").Each(func(_ int, sel *goquery.Selection) {
+		node := sel.Nodes[0]
+
+		if normalizeSpace(sel.Text()) == value && !sel.Children().FilterFunction(func(_ int, child *goquery.Selection) bool {
+			return normalizeSpace(child.Text()) == value
+		}).Is("*") {
+			byText = append(byText, exampleMatch{value: value, node: node})
+		}
+
+		for _, attr := range node.Attr {
+			if attr.Key != "class" && attr.Key != "style" && normalizeSpace(attr.Val) == value {
+				byAttr = append(byAttr, exampleMatch{value: value, node: node, attr: attr.Key})
+			}
+		}
+	})
+
+	if len(byText) != 0 {
+		return byText
+	}
+
+	return byAttr
+}
+
+// repeatingContainers returns the children of listRoot which contain the nodes,
+// and a func to get the container of a node
+func repeatingContainers(listRoot *html.Node, nodes []*html.Node) ([]*html.Node, func(*html.Node) *html.Node) {
+	owner := make(map[*html.Node]*html.Node)
+
+	var containers []*html.Node
+
+	for _, node := range nodes {
+		if node == listRoot {
+			return nil, nil
+		}
+
+		c := node
+		for c.Parent != listRoot {
+			c = c.Parent
+		}
+
+		if _, ok := owner[c]; !ok {
+			containers = append(containers, c)
+		}
+
+		owner[node] = c
+		owner[c] = c
+	}
+
+	return containers, func(n *html.Node) *html.Node { return owner[n] }
+}
+
+// isRepeating checks containers are of the same tag, and at least one field is found in more than one container
+func isRepeating(containers []*html.Node, owner func(*html.Node) *html.Node, matches map[string][]exampleMatch) bool {
+	if len(containers) < 2 { //nolint:gomnd
+		return false
+	}
+
+	for _, c := range containers[1:] {
+		if c.Data != containers[0].Data {
+			return false
+		}
+	}
+
+	for _, arr := range matches {
+		seen := make(map[*html.Node]bool)
+		for _, m := range arr {
+			seen[owner(m.node)] = true
+		}
+
+		if len(seen) > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func bestContainerCandidate(root *goquery.Selection, containers []*html.Node) SelectorCandidate {
+	listRoot := containers[0].Parent
+
+	tag := containers[0].Data
+
+	type proposal struct {
+		sel       string
+		stability float64
+	}
+
+	var proposals []proposal
+
+	classes := commonStableClasses(containers)
+	for _, cls := range classes {
+		proposals = append(proposals, proposal{tag + "." + cls, _stabilityClass})
+	}
+
+	if len(classes) > 1 {
+		proposals = append(proposals, proposal{tag + "." + strings.Join(classes, "."), _stabilityClass})
+	}
+
+	for _, attr := range commonStableAttrs(containers) {
+		proposals = append(proposals, proposal{tag + attr, _stabilityAttr})
+	}
+
+	proposals = append(proposals, proposal{nodeSelector(listRoot, true) + " > " + tag, _stabilityTag})
+
+	want := make(map[*html.Node]bool)
+	for _, c := range containers {
+		want[c] = true
+	}
+
+	var cands []SelectorCandidate
+
+	for _, prop := range proposals {
+		matched := root.Find(prop.sel).Nodes
+
+		hits, siblings := 0, 0
+
+		for _, n := range matched {
+			if want[n] {
+				hits++
+			}
+
+			if n.Parent == listRoot {
+				siblings++
+			}
+		}
+
+		if hits != len(containers) {
+			continue
+		}
+
+		// nodes matched outside the list are likely noise
+		precision := float64(siblings) / float64(len(matched))
+		cands = append(cands, SelectorCandidate{
+			Locator: prop.sel,
+			Score:   prop.stability + 2*precision,
+			Hits:    hits,
+			Matches: len(matched),
+		})
+	}
+
+	if len(cands) == 0 {
+		// CSSPath is always valid, but only the examples' containers are matched
+		return SelectorCandidate{Locator: CSSPath(listRoot) + " > " + tag, Hits: len(containers), Matches: len(containers)}
+	}
+
+	sortCandidates(cands)
+
+	return cands[0]
+}
+
+func rankFieldCandidates(
+	matches []exampleMatch, scopeOf func(*html.Node) *html.Node, containers []*html.Node, withID bool,
+) []SelectorCandidate {
+	type proposal struct {
+		sel, attr string
+		stability float64
+	}
+
+	var proposals []proposal
+
+	seen := make(map[string]bool)
+	propose := func(sel, attr string, stability float64) {
+		if sel == "" || seen[sel+"@"+attr] {
+			return
+		}
+
+		seen[sel+"@"+attr] = true
+		proposals = append(proposals, proposal{sel, attr, stability})
+	}
+
+	for _, m := range matches {
+		node := m.node
+
+		// the value is in the container itself, so no locator is needed
+		if node == scopeOf(node) {
+			if !seen["@"+m.attr] {
+				seen["@"+m.attr] = true
+				proposals = append(proposals, proposal{"", m.attr, _stabilityAttr})
+			}
+
+			continue
+		}
+
+		if id := attrValue(node, "id"); withID && isStableClass(id) {
+			propose("#"+id, m.attr, _stabilityAttr)
+		}
+
+		for _, attr := range stableAttrs(node) {
+			propose(node.Data+attr, m.attr, _stabilityAttr)
+		}
+
+		classes := stableClasses(node)
+		for _, cls := range classes {
+			propose(node.Data+"."+cls, m.attr, _stabilityClass)
+		}
+
+		if len(classes) > 1 {
+			propose(node.Data+"."+strings.Join(classes, "."), m.attr, _stabilityClass)
+		}
+
+		propose(node.Data, m.attr, _stabilityTag)
+
+		if parent := node.Parent; parent != nil && parent != scopeOf(node) && parent.Type == html.ElementNode {
+			if sel := nodeSelector(parent, false); sel != parent.Data {
+				propose(sel+" > "+node.Data, m.attr, _stabilityClass-_penaltyNested)
+			}
+		}
+	}
+
+	cands := make([]SelectorCandidate, 0, len(proposals))
+
+	for _, prop := range proposals {
+		hits := 0
+
+		for _, m := range matches {
+			elem := locateInScope(scopeOf(m.node), prop.sel).First()
+			if elem.Length() != 0 && normalizeSpace(rawValue(elem, prop.attr)) == m.value {
+				hits++
+			}
+		}
+
+		if hits == 0 {
+			continue
+		}
+
+		covered, ambiguous := 0, 0
+
+		for _, c := range containers {
+			n := locateInScope(c, prop.sel).Length()
+			if n > 0 {
+				covered++
+			}
+
+			if n > 1 {
+				ambiguous++
+			}
+		}
+
+		coverage := float64(covered) / float64(len(containers))
+		cands = append(cands, SelectorCandidate{
+			Locator: prop.sel,
+			Attr:    prop.attr,
+			Score: 10*float64(hits)/float64(len(matches)) + prop.stability +
+				2*coverage - float64(ambiguous)/float64(len(containers)),
+			Hits:    hits,
+			Matches: covered,
+			Multi:   covered > 0 && ambiguous == covered,
+		})
+	}
+
+	sortCandidates(cands)
+
+	return cands
+}
+
+// locateInScope returns the nodes of sel in scope, or scope itself if sel is empty
+func locateInScope(scope *html.Node, sel string) *goquery.Selection {
+	elems := goquery.NewDocumentFromNode(scope).Selection
+	if sel == "" {
+		return elems
+	}
+
+	return elems.Find(sel)
+}
+
+func sortCandidates(cands []SelectorCandidate) {
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].Score != cands[j].Score {
+			return cands[i].Score > cands[j].Score
+		}
+
+		if len(cands[i].Locator) != len(cands[j].Locator) {
+			return len(cands[i].Locator) < len(cands[j].Locator)
+		}
+
+		return cands[i].Locator < cands[j].Locator
+	})
+}
+
+func suggestYaml(fields []string, report *SuggestReport, opt *SuggestOpts) []byte {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	parent := root
+
+	if report.Container != nil {
+		stub := &yaml.Node{Kind: yaml.MappingNode}
+		appendYamlPair(stub, Locator, yamlScalar(report.Container.Locator))
+		appendYamlPair(stub, Index, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "~"})
+		appendYamlPair(root, opt.rootKey, stub)
+
+		parent = stub
+	}
+
+	for _, field := range fields {
+		cands := report.Fields[field]
+		if len(cands) == 0 {
+			continue
+		}
+
+		best := cands[0]
+		node := &yaml.Node{Kind: yaml.MappingNode}
+
+		var alts []string
+
+		for _, c := range cands[1:] {
+			if c.Locator != "" {
+				alts = append(alts, c.Locator)
+			}
+		}
+
+		// the value of container itself has no locator
+		if best.Locator != "" {
+			locator := yamlScalar(best.Locator)
+			if len(alts) != 0 {
+				locator.LineComment = "or: " + strings.Join(alts, ", ")
+			}
+
+			appendYamlPair(node, Locator, locator)
+		}
+
+		if best.Multi {
+			appendYamlPair(node, Index, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "~"})
+		}
+
+		if best.Attr != "" {
+			appendYamlPair(node, Attr, yamlScalar(best.Attr))
+		}
+
+		appendYamlPair(parent, field, node)
+	}
+
+	return encodeYaml(root)
+}
+
+func encodeYaml(root *yaml.Node) []byte {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2) //nolint:gomnd
+	PanicIfErr(enc.Encode(root))
+	PanicIfErr(enc.Close())
+
+	return buf.Bytes()
+}
+
+func appendYamlPair(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, yamlScalar(key), value)
+}
+
+func yamlScalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func rawValue(sel *goquery.Selection, attr string) string {
+	if attr == "" {
+		return strings.TrimSpace(sel.Text())
+	}
+
+	return sel.AttrOr(attr, "")
+}
+
+// nodeSelector returns tag with id (if withID) or stable classes of node, CSSPath is returned if none found
+func nodeSelector(node *html.Node, withID bool) string {
+	if id := attrValue(node, "id"); withID && isStableClass(id) {
+		return "#" + id
+	}
+
+	if withID && len(stableClasses(node)) == 0 {
+		return CSSPath(node)
+	}
+
+	return nodeStep(node)
+}
+
+func lowestCommonAncestor(nodes []*html.Node) *html.Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	ancestors := func(n *html.Node) []*html.Node {
+		var arr []*html.Node
+		for ; n != nil; n = n.Parent {
+			arr = append([]*html.Node{n}, arr...)
+		}
+
+		return arr
+	}
+
+	common := ancestors(nodes[0])
+
+	for _, n := range nodes[1:] {
+		path := ancestors(n)
+
+		i := 0
+		for i < len(common) && i < len(path) && common[i] == path[i] {
+			i++
+		}
+
+		common = common[:i]
+	}
+
+	if len(common) == 0 {
+		return nil
+	}
+
+	return common[len(common)-1]
+}
+
+func commonStableClasses(nodes []*html.Node) []string {
+	counts := make(map[string]int)
+
+	for _, n := range nodes {
+		for _, cls := range stableClasses(n) {
+			counts[cls]++
+		}
+	}
+
+	var arr []string
+
+	for _, cls := range stableClasses(nodes[0]) {
+		if counts[cls] == len(nodes) {
+			arr = append(arr, cls)
+		}
+	}
+
+	return arr
+}
+
+func commonStableAttrs(nodes []*html.Node) []string {
+	counts := make(map[string]int)
+
+	for _, n := range nodes {
+		for _, attr := range stableAttrs(n) {
+			counts[attr]++
+		}
+	}
+
+	var arr []string
+
+	for _, attr := range stableAttrs(nodes[0]) {
+		if counts[attr] == len(nodes) {
+			arr = append(arr, attr)
+		}
+	}
+
+	return arr
+}
+
+func stableClasses(node *html.Node) []string {
+	var arr []string
+
+	for _, cls := range strings.Fields(attrValue(node, "class")) {
+		if isStableClass(cls) {
+			arr = append(arr, cls)
+		}
+	}
+
+	return arr
+}
+
+func isStableClass(name string) bool {
+	return name != "" && !_stateClasses[name] && !_unstableClassRegex.MatchString(name)
+}
+
+// stableAttrs returns attribute selectors like [itemprop="title"]
+func stableAttrs(node *html.Node) []string {
+	var arr []string
+
+	for _, attr := range node.Attr {
+		if !_stableAttrRegex.MatchString(attr.Key) || attr.Val == "" || strings.ContainsAny(attr.Val, `"\`) {
+			continue
+		}
+
+		arr = append(arr, fmt.Sprintf(`[%s="%s"]`, attr.Key, attr.Val))
+	}
+
+	return arr
+}
+
+func attrValue(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+func tagPath(node *html.Node) string {
+	var arr []string
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		arr = append(arr, n.Data)
+	}
+
+	return strings.Join(arr, "<")
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,119 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _suggestHTML = `<html><head><title>Go Developer</title></head><body>
+<ul class="jobs">
+  <li class="job css-1x2y3z">
+    <h2 class="title">Go Developer</h2>
+    <div class="meta"><span>Zurich</span><span itemprop="salary">100k</span></div>
+    <a class="apply" href="/jobs/1">Apply</a>
+  </li>
+  <li class="job css-9a8b7c">
+    <h2 class="title">Rust Developer</h2>
+    <div class="meta"><span>Basel</span><span itemprop="salary">120k</span></div>
+    <a class="apply" href="/jobs/2">Apply</a>
+  </li>
+  <li class="job css-5d6e7f">
+    <h2 class="title">Java Developer</h2>
+    <div class="meta"><span>Bern</span></div>
+    <a class="apply" href="/jobs/3">Apply</a>
+  </li>
+</ul>
+</body></html>`
+
+func TestSuggest(t *testing.T) {
+	assert := assert.New(t)
+
+	yml, report := Suggest([]byte(_suggestHTML), map[string][]string{
+		"title":    {"Go Developer", "Rust Developer"},
+		"location": {"Zurich", "Basel"},
+		"salary":   {"100k"},
+		"url":      {"/jobs/1", "/jobs/2"},
+		"company":  {"Acme"},
+	}, WithSuggestRootKey("jobs"))
+
+	assert.Equal("li.job", report.Container.Locator)
+	assert.Equal(3, report.Container.Matches)
+	assert.Equal(`span[itemprop="salary"]`, report.Fields["salary"][0].Locator)
+	assert.Equal("href", report.Fields["url"][0].Attr)
+	assert.Equal(map[string][]string{"company": {"Acme"}}, report.Unmatched)
+
+	p := NewHTMLParser([]byte(_suggestHTML), yml)
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"location": "Zurich", "salary": "100k", "title": "Go Developer", "url": "/jobs/1"},
+		{"location": "Basel", "salary": "120k", "title": "Rust Developer", "url": "/jobs/2"},
+		{"location": "Bern", "salary": "", "title": "Java Developer", "url": "/jobs/3"},
+	}, p.ParsedData["jobs"], string(yml))
+}
+
+func TestSuggestWithoutContainer(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body><div id="main"><h1 class="name">Acme</h1><p data-field="city">Zurich</p></div></body></html>`
+
+	yml, report := Suggest([]byte(rawHTML), map[string][]string{
+		"name": {"Acme"},
+		"city": {"Zurich"},
+	})
+
+	assert.Nil(report.Container)
+	assert.Equal(`p[data-field="city"]`, report.Fields["city"][0].Locator)
+
+	p := NewHTMLParser([]byte(rawHTML), yml)
+	p.DoParse()
+
+	assert.Equal(map[string]any{"name": "Acme", "city": "Zurich"}, p.ParsedData, string(yml))
+}
+
+func TestSuggestContainerValue(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body><ul><li>Alpha</li><li>Beta</li><li>Gamma</li></ul></body></html>`
+
+	yml, report := Suggest([]byte(rawHTML), map[string][]string{
+		"title": {"Alpha", "Beta"},
+	})
+
+	assert.Equal("", report.Fields["title"][0].Locator)
+	assert.Empty(report.Unmatched)
+
+	p := NewHTMLParser([]byte(rawHTML), yml)
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "Alpha"}, {"title": "Beta"}, {"title": "Gamma"},
+	}, p.ParsedData[_suggestRootKey], string(yml))
+}
+
+func TestSuggestMultiValue(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body><ul>
+<li class="job"><h2>Go Developer</h2><span class="tag">go</span><span class="tag">k8s</span></li>
+<li class="job"><h2>Rust Developer</h2><span class="tag">rust</span><span class="tag">wasm</span></li>
+</ul></body></html>`
+
+	yml, report := Suggest([]byte(rawHTML), map[string][]string{
+		"title": {"Go Developer", "Rust Developer"},
+		"tags":  {"go", "rust"},
+	})
+
+	assert.True(report.Fields["tags"][0].Multi)
+	assert.False(report.Fields["title"][0].Multi)
+	assert.Contains(string(yml), "_index: ~")
+
+	p := NewHTMLParser([]byte(rawHTML), yml)
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "Go Developer", "tags": []any{"go", "k8s"}},
+		{"title": "Rust Developer", "tags": []any{"rust", "wasm"}},
+	}, p.ParsedData[_suggestRootKey], string(yml))
+}
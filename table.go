@@ -0,0 +1,244 @@
+package xparse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// options of _table, check Table for more info
+const (
+	_tableHeader = "header"
+	_tableRename = "rename"
+
+	// _tableAutoHeader is used when header is not set
+	_tableAutoHeader = -1
+)
+
+// handleTable parses the table located by cfg as a list of maps, check Table for more info
+func (p *HTMLParser) handleTable(key string, cfg map[string]any, selection *goquery.Selection, data map[string]any, layer int) {
+	table := selection
+	if loc, ok := mustCfgLocator(cfg).(string); ok && loc != "" {
+		table = selection.Find(loc).First()
+	}
+
+	if goquery.NodeName(table) != "table" {
+		table = table.Find("table").First()
+	}
+
+	headerRow, rename := _tableAutoHeader, map[string]string{}
+
+	switch opt := cfg[Table].(type) {
+	case bool:
+	case map[string]any:
+		if v, ok := opt[_tableHeader]; ok {
+			n, err := cast.ToIntE(v)
+			if err != nil || n < 0 {
+				panic(xpretty.Redf("%s.%s of %s must be an int >= 0, but got %v", Table, _tableHeader, key, v))
+			}
+
+			headerRow = n
+		}
+
+		rename = cast.ToStringMapString(opt[_tableRename])
+	default:
+		panic(xpretty.Redf("%s of %s must be bool or map, but got (%T: %v)", Table, key, opt, opt))
+	}
+
+	grid := tableGrid(table)
+	if headerRow == _tableAutoHeader {
+		headerRow = guessHeaderRow(grid)
+	}
+
+	var headers []string
+
+	if headerRow < len(grid) {
+		headers = tableHeaders(grid[headerRow], rename)
+	}
+
+	columns := p.tableColumns(cfg, headers)
+
+	var rows []map[string]any
+
+	for _, row := range grid[min(headerRow+1, len(grid)):] {
+		if isBlankRow(row) {
+			continue
+		}
+
+		i := len(rows)
+		if layer == _layerForRank {
+			p.FocusedStub = row[0].Parent()
+			p.setRank(cfg)
+		}
+
+		item := make(map[string]any)
+
+		p.pushOutputIndex(i)
+
+		for _, col := range columns {
+			cell := table.Slice(0, 0)
+			if col.index >= 0 && col.index < len(row) {
+				cell = row[col.index]
+			}
+
+			// column config is resolved relative to the cell, so _locator and _index work in it
+			p.parseDom(col.key, col.cfg, cell, item, _layerForOthers)
+		}
+
+		p.popOutputIndex()
+
+		rows = append(rows, item)
+	}
+
+	data[key] = rows
+}
+
+type tableColumn struct {
+	key   string
+	index int
+	cfg   map[string]any
+}
+
+// tableColumns returns child keys as columns if any, or all headers
+func (p *HTMLParser) tableColumns(cfg map[string]any, headers []string) []tableColumn {
+	indexOf := func(header string) int {
+		for i, h := range headers {
+			if h == header {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	var columns []tableColumn
+
+	for k, v := range cfg {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+
+		sub, _ := v.(map[string]any)
+		if sub == nil {
+			sub = map[string]any{}
+		}
+
+		header := k
+		if h, ok := sub[TableHeader]; ok {
+			header = normalizeSpace(cast.ToString(h))
+		}
+
+		columns = append(columns, tableColumn{key: k, index: indexOf(header), cfg: sub})
+	}
+
+	if len(columns) != 0 {
+		return columns
+	}
+
+	for i, h := range headers {
+		columns = append(columns, tableColumn{key: h, index: i, cfg: map[string]any{}})
+	}
+
+	return columns
+}
+
+// tableGrid returns the cells of each row, a cell with colspan or rowspan is repeated in all its slots,
+// rows of nested tables are ignored
+func tableGrid(table *goquery.Selection) [][]*goquery.Selection {
+	type span struct {
+		cell *goquery.Selection
+		left int
+	}
+
+	var grid [][]*goquery.Selection
+
+	pending := make(map[int]*span)
+
+	table.Find("tr").FilterFunction(func(_ int, tr *goquery.Selection) bool {
+		return tr.Closest("table").IsSelection(table)
+	}).Each(func(_ int, tr *goquery.Selection) {
+		var row []*goquery.Selection
+
+		// fill the slots taken by rowspan of former rows
+		fill := func() {
+			for sp, ok := pending[len(row)]; ok; sp, ok = pending[len(row)] {
+				row = append(row, sp.cell)
+
+				if sp.left--; sp.left == 0 {
+					delete(pending, len(row)-1)
+				}
+			}
+		}
+
+		tr.ChildrenFiltered("th,td").Each(func(_ int, cell *goquery.Selection) {
+			fill()
+
+			colspan := max(1, cast.ToInt(cell.AttrOr("colspan", "1")))
+			rowspan := max(1, cast.ToInt(cell.AttrOr("rowspan", "1")))
+
+			for i := 0; i < colspan; i++ {
+				if rowspan > 1 {
+					pending[len(row)] = &span{cell: cell, left: rowspan - 1}
+				}
+
+				row = append(row, cell)
+			}
+		})
+
+		fill()
+
+		grid = append(grid, row)
+	})
+
+	return grid
+}
+
+// guessHeaderRow returns the first row of all th, or 0
+func guessHeaderRow(grid [][]*goquery.Selection) int {
+	for i, row := range grid {
+		allTH := len(row) != 0
+		for _, cell := range row {
+			allTH = allTH && goquery.NodeName(cell) == "th"
+		}
+
+		if allTH {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// tableHeaders returns the normalized and renamed headers, duplicated ones are suffixed with _2, _3...
+func tableHeaders(row []*goquery.Selection, rename map[string]string) []string {
+	headers := make([]string, 0, len(row))
+	seen := make(map[string]int)
+
+	for i, cell := range row {
+		h := normalizeSpace(cell.Text())
+		if h == "" {
+			h = fmt.Sprintf("column_%d", i+1)
+		}
+
+		if v, ok := rename[h]; ok {
+			h = v
+		}
+
+		headers = append(headers, uniqueFieldName(seen, h))
+	}
+
+	return headers
+}
+
+func isBlankRow(row []*goquery.Selection) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell.Text()) != "" {
+			return false
+		}
+	}
+
+	return true
+}
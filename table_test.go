@@ -0,0 +1,86 @@
+package xparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coghost/xpretty"
+	"github.com/stretchr/testify/assert"
+)
+
+const _tableHTML = `<html><body>
+<table class="jobs">
+  <thead><tr><th>Job Title</th><th>City</th><th colspan="2">Salary</th></tr></thead>
+  <tbody>
+    <tr><td><a href="/j/1">Go Dev</a></td><td rowspan="2">Zurich</td><td>100</td><td>CHF</td></tr>
+    <tr><td><a href="/j/2">Rust Dev</a></td><td>120</td><td>CHF</td></tr>
+    <tr><td></td><td></td><td></td><td></td></tr>
+    <tr><td><a href="/j/3">Java Dev</a></td><td>Bern</td><td colspan="2">n/a</td></tr>
+  </tbody>
+</table>
+</body></html>`
+
+func TestHTMLTableAllColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: table.jobs
+  _table:
+    rename: {Job Title: title}
+`
+	p := NewHTMLParser([]byte(_tableHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "Go Dev", "City": "Zurich", "Salary": "100", "Salary_2": "CHF"},
+		{"title": "Rust Dev", "City": "Zurich", "Salary": "120", "Salary_2": "CHF"},
+		{"title": "Java Dev", "City": "Bern", "Salary": "n/a", "Salary_2": "n/a"},
+	}, p.ParsedData["jobs"])
+}
+
+func TestHTMLTableColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: table.jobs
+  _table: true
+  url:
+    _header: Job Title
+    _locator: a
+    _index: 0
+    _attr: href
+  city:
+    _header: City
+    _attr_refine: upper
+  salary:
+    _header: Salary
+    _type: i
+  missing:
+`
+	p := NewHTMLParser([]byte(_tableHTML), []byte(yml))
+	p.Refiners["upper"] = func(args ...any) any { return strings.ToUpper(args[0].(string)) }
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"url": "/j/1", "city": "ZURICH", "salary": 100, "missing": ""},
+		{"url": "/j/2", "city": "ZURICH", "salary": 120, "missing": ""},
+		{"url": "/j/3", "city": "BERN", "salary": 0, "missing": ""},
+	}, p.ParsedData["jobs"])
+}
+
+func TestHTMLTableInvalidHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: table.jobs
+  _table:
+    header: -2
+`
+	p := NewHTMLParser([]byte(_tableHTML), []byte(yml))
+	assert.PanicsWithValue(xpretty.Redf("%s.%s of %s must be an int >= 0, but got %v", Table, _tableHeader, "jobs", -2), func() {
+		p.DoParse()
+	})
+}
@@ -0,0 +1,209 @@
+package xparse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+// modes of _text, check Text for more info
+const (
+	_textInner    = "inner"
+	_textVisible  = "visible"
+	_textBlock    = "block"
+	_textCollapse = "collapse"
+	_textUnicode  = "unicode"
+
+	// _rawText is the parser-wide default of _text
+	_rawText = "__raw.text"
+)
+
+var (
+	_invisibleTags = map[string]bool{
+		"script": true, "style": true, "noscript": true, "template": true, "head": true,
+	}
+
+	_blockTags = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true, "dd": true, "div": true,
+		"dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+		"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+		"pre": true, "section": true, "table": true, "tr": true, "ul": true, "br": true,
+	}
+
+	_hiddenStyleRegex = regexp.MustCompile(`(?i)(display\s*:\s*none|visibility\s*:\s*hidden)`)
+	_zeroWidthChars   = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\u2060", "", "\ufeff", "")
+	_spacesRegex      = regexp.MustCompile(`[\p{Z}\t\f\r\v]+`)
+	_newlinesRegex    = regexp.MustCompile(`[\p{Z}\s]*\n[\p{Z}\s]*`)
+)
+
+type textOptions struct {
+	visible  bool
+	block    bool
+	collapse bool
+	unicode  norm.Form
+	// normalize is false when unicode is not set
+	normalize bool
+}
+
+// BindTextMode sets the default _text of all leaves, the format is same with _text
+func (p *Parser) BindTextMode(mode any) {
+	p.textMode = mode
+}
+
+// newTextOptions parses _text, which is a mode, a list of modes, or a map like {unicode: NFKC}
+func newTextOptions(key string, mode any) *textOptions {
+	opts := &textOptions{}
+
+	var apply func(v any)
+
+	apply = func(v any) {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			switch v {
+			case _textInner:
+			case _textVisible:
+				opts.visible = true
+			case _textBlock:
+				opts.block = true
+			case _textCollapse:
+				opts.collapse = true
+			default:
+				panic(xpretty.Redf("unknown mode (%s) in %s of %s", v, Text, key))
+			}
+		case []any:
+			for _, m := range v {
+				apply(m)
+			}
+		case map[string]any:
+			for k, arg := range v {
+				if k != _textUnicode {
+					if cast.ToBool(arg) {
+						apply(k)
+					}
+
+					continue
+				}
+
+				opts.unicode, opts.normalize = unicodeForm(key, cast.ToString(arg)), true
+			}
+		default:
+			panic(xpretty.Redf("%s of %s must be string/list/map, but got (%T: %v)", Text, key, v, v))
+		}
+	}
+
+	apply(mode)
+
+	return opts
+}
+
+func unicodeForm(key, name string) norm.Form {
+	switch strings.ToUpper(name) {
+	case "NFC":
+		return norm.NFC
+	case "NFD":
+		return norm.NFD
+	case "NFKC":
+		return norm.NFKC
+	case "NFKD":
+		return norm.NFKD
+	default:
+		panic(xpretty.Redf("unknown unicode form (%s) in %s of %s, only NFC/NFD/NFKC/NFKD are supported", name, Text, key))
+	}
+}
+
+// selectionText returns text of selection by _text (or the parser-wide default), it's selection.Text() if neither is set
+func (p *HTMLParser) selectionText(key string, cfg map[string]any, selection *goquery.Selection) string {
+	mode, ok := cfg[Text]
+	if !ok {
+		mode = p.textMode
+	}
+
+	if mode == nil {
+		return selection.Text()
+	}
+
+	return newTextOptions(key, mode).text(selection)
+}
+
+func (o *textOptions) text(selection *goquery.Selection) string {
+	var (
+		buf  strings.Builder
+		walk func(n *html.Node)
+	)
+
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if o.visible && isInvisible(n) {
+				return
+			}
+
+			if o.block && _blockTags[n.Data] {
+				buf.WriteString("\n")
+				defer buf.WriteString("\n")
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	for _, n := range selection.Nodes {
+		walk(n)
+	}
+
+	txt := buf.String()
+
+	if o.normalize {
+		txt = o.unicode.String(txt)
+	}
+
+	if o.collapse {
+		txt = collapseText(txt, o.block)
+	}
+
+	return txt
+}
+
+func isInvisible(n *html.Node) bool {
+	if _invisibleTags[n.Data] {
+		return true
+	}
+
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "hidden",
+			a.Key == "aria-hidden" && a.Val == "true",
+			a.Key == "style" && _hiddenStyleRegex.MatchString(a.Val):
+			return true
+		}
+	}
+
+	return false
+}
+
+// collapseText removes zero-width chars and collapses whitespace (&nbsp; included) to one space,
+// newlines are kept (one at most) if keepLines
+func collapseText(s string, keepLines bool) string {
+	s = _zeroWidthChars.Replace(s)
+
+	if !keepLines {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	s = _spacesRegex.ReplaceAllString(s, " ")
+	s = _newlinesRegex.ReplaceAllString(s, "\n")
+
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,69 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _textHTML = `<html><body>
+<div class="desc"><p>Hello&nbsp;&nbsp;world</p><p>Second&#8203; line</p><script>var a = 1;</script><span style="display: none">hidden</span><ul><li>one</li><li>two</li></ul></div>
+<div class="full">ＡＢＣ １２３</div>
+</body></html>`
+
+func TestHTMLTextModes(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+raw:
+  _locator: div.desc
+collapse:
+  _locator: div.desc
+  _text: [visible, collapse]
+block:
+  _locator: div.desc
+  _text: [visible, block, collapse]
+full:
+  _locator: div.full
+  _text: [collapse, unicode: NFKC]
+`
+	p := NewHTMLParser([]byte(_textHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal("Hello  worldSecond\u200b linevar a = 1;hiddenonetwo", p.ParsedData["raw"])
+	assert.Equal("Hello worldSecond lineonetwo", p.ParsedData["collapse"])
+	assert.Equal("Hello world\nSecond line\none\ntwo", p.ParsedData["block"])
+	assert.Equal("ABC 123", p.ParsedData["full"])
+}
+
+func TestHTMLTextDefaultMode(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  text: [visible, block, collapse]
+desc:
+  _locator: div.desc
+inner:
+  _locator: div.desc li
+  _index: 0
+  _text: inner
+`
+	p := NewHTMLParser([]byte(_textHTML), []byte(yml))
+	p.DoParse()
+
+	assert.Equal("Hello world\nSecond line\none\ntwo", p.ParsedData["desc"])
+	assert.Equal("one", p.ParsedData["inner"])
+
+	p = NewHTMLParser([]byte(_textHTML), []byte(`desc: {_locator: div.desc}`))
+	p.BindTextMode("collapse")
+	p.DoParse()
+
+	assert.Equal("Hello worldSecond linevar a = 1;hiddenonetwo", p.ParsedData["desc"])
+
+	// the shorthand of locator uses the parser-wide mode too
+	p = NewHTMLParser([]byte(_textHTML), []byte("__raw:\n  text: [visible, collapse]\npage:\n  desc: div.desc\n"))
+	p.DoParse()
+
+	assert.Equal(map[string]any{"desc": "Hello worldSecond lineonetwo"}, p.ParsedData["page"])
+}
@@ -0,0 +1,202 @@
+package xparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/tidwall/gjson"
+)
+
+type TraceKind string
+
+const (
+	// TraceStub is emitted when a key (stub or leaf) is entered
+	TraceStub TraceKind = "stub"
+	// TraceLocator is emitted when a locator is evaluated, Matches is the count of matched nodes
+	TraceLocator TraceKind = "locator"
+	// TraceIndex is emitted when _index is applied, Matches is the count of selected nodes
+	TraceIndex TraceKind = "index"
+	// TraceStep is emitted after a refining step like _strip/_attr_refine/_attr_regex/_attr_python/_attr_js/_type
+	TraceStep TraceKind = "step"
+	// TraceValue is emitted when a leaf value is saved to ParsedData
+	TraceValue TraceKind = "value"
+)
+
+// TraceEvent is one step of parsing
+type TraceEvent struct {
+	Kind TraceKind
+
+	// Path is the output path in ParsedData, like jobs.0.title
+	Path string
+	// KeyPath is the key path in yaml config, like jobs.title
+	KeyPath string
+
+	// Locator and Matches are set for TraceLocator
+	Locator string
+	// Index is set for TraceIndex, nil if _index is null, "(absent)" if _index is not existed
+	Index   any
+	Matches int
+
+	// Step is set for TraceStep, like "_strip", "_attr_regex", "_type:i" or the refiner name
+	Step string
+	// Before and After are set for TraceStep, only After is set for TraceValue
+	Before any
+	After  any
+
+	Duration time.Duration
+}
+
+// Tracer receives every TraceEvent while parsing
+type Tracer interface {
+	Trace(ev TraceEvent)
+}
+
+// TracerFunc is an adapter to allow the use of ordinary functions as Tracer
+type TracerFunc func(ev TraceEvent)
+
+func (f TracerFunc) Trace(ev TraceEvent) {
+	f(ev)
+}
+
+// TraceRecorder is a Tracer which saves all events
+type TraceRecorder struct {
+	Events []TraceEvent
+}
+
+func (r *TraceRecorder) Trace(ev TraceEvent) {
+	r.Events = append(r.Events, ev)
+}
+
+// Filter returns the events of path (output path), all events are returned if path is empty
+func (r *TraceRecorder) Filter(path string) []TraceEvent {
+	var arr []TraceEvent
+
+	for _, ev := range r.Events {
+		if path == "" || ev.Path == path {
+			arr = append(arr, ev)
+		}
+	}
+
+	return arr
+}
+
+type textTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer returns a Tracer which writes events as indented lines to w, like:
+//
+//	stub jobs
+//	  locator jobs: "div.job" -> 2 matches
+//	  index jobs: <nil> -> 2 selected
+//	    stub jobs.0.title
+//	    locator jobs.0.title: "h2" -> 0 matches
+//	    value jobs.0.title: ""
+func NewTextTracer(w io.Writer) Tracer {
+	return &textTracer{w: w}
+}
+
+func (t *textTracer) Trace(ev TraceEvent) {
+	indent := strings.Repeat("  ", strings.Count(ev.KeyPath, "."))
+
+	var line string
+
+	switch ev.Kind {
+	case TraceStub:
+		line = fmt.Sprintf("stub %s", ev.Path)
+	case TraceLocator:
+		line = fmt.Sprintf("locator %s: %q -> %d matches (%s)", ev.Path, ev.Locator, ev.Matches, ev.Duration)
+	case TraceIndex:
+		line = fmt.Sprintf("index %s: %v -> %d selected", ev.Path, ev.Index, ev.Matches)
+	case TraceStep:
+		line = fmt.Sprintf("step %s: %s %#v -> %#v (%s)", ev.Path, ev.Step, ev.Before, ev.After, ev.Duration)
+	case TraceValue:
+		line = fmt.Sprintf("value %s: %#v", ev.Path, ev.After)
+	}
+
+	fmt.Fprintln(t.w, indent+line)
+}
+
+// BindTracer sets the tracer, nil to disable tracing
+func (p *Parser) BindTracer(t Tracer) {
+	p.tracer = t
+}
+
+func (p *Parser) emit(ev TraceEvent) {
+	if p.tracer == nil {
+		return
+	}
+
+	ev.Path = strings.Join(p.outputPath, ".")
+	ev.KeyPath = strings.Join(p.keyPath, ".")
+
+	p.tracer.Trace(ev)
+}
+
+func (p *Parser) traceStub() {
+	p.emit(TraceEvent{Kind: TraceStub})
+}
+
+func (p *Parser) traceLocator(locator string, matches int, start time.Time) {
+	if p.tracer == nil {
+		return
+	}
+
+	p.emit(TraceEvent{Kind: TraceLocator, Locator: locator, Matches: matches, Duration: time.Since(start)})
+}
+
+func (p *Parser) traceIndex(cfg map[string]any, selected *any) {
+	if p.tracer == nil {
+		return
+	}
+
+	index, existed := cfgIndex(cfg)
+	if !existed {
+		index = "(absent)"
+	}
+
+	p.emit(TraceEvent{Kind: TraceIndex, Index: index, Matches: countMatches(*selected)})
+}
+
+// traceStep is used with defer, to record a refining step with the value before and after refining:
+//
+//	defer p.traceStep(name, raw, time.Now(), &refined)
+func (p *Parser) traceStep(name string, before any, start time.Time, after *any) {
+	p.recordRefiner(name)
+
+	if p.tracer == nil {
+		return
+	}
+
+	p.emit(TraceEvent{Kind: TraceStep, Step: name, Before: before, After: *after, Duration: time.Since(start)})
+}
+
+func (p *Parser) traceValue(value any) {
+	p.emit(TraceEvent{Kind: TraceValue, After: value})
+}
+
+func countMatches(elems any) int {
+	switch dom := elems.(type) {
+	case *goquery.Selection:
+		return len(dom.Nodes)
+	case []*goquery.Selection:
+		return len(dom)
+	case gjson.Result:
+		if dom.IsArray() {
+			return len(dom.Array())
+		}
+
+		if dom.Exists() {
+			return 1
+		}
+
+		return 0
+	case []gjson.Result:
+		return len(dom)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,88 @@
+package xparse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceRecorder(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body>
+<div class="job"><h2>Job 10</h2></div>
+<div class="job"><h2>Job 20</h2></div>
+</body></html>`
+
+	yml := `
+jobs:
+  _locator: div.job
+  _index: ~
+  id:
+    _locator: h2
+    _attr_regex: \d+
+    _type: i
+`
+	rec := &TraceRecorder{}
+
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.BindTracer(rec)
+	p.DoParse()
+
+	jobs := rec.Filter("jobs")
+	assert.Equal(TraceStub, jobs[0].Kind)
+	assert.Equal(TraceEvent{Kind: TraceLocator, Path: "jobs", KeyPath: "jobs", Locator: "div.job", Matches: 2}, withoutDuration(jobs[1]))
+	assert.Equal(TraceIndex, jobs[2].Kind)
+	assert.Nil(jobs[2].Index)
+	assert.Equal(2, jobs[2].Matches)
+
+	var steps []string
+
+	for _, ev := range rec.Filter("jobs.1.id") {
+		if ev.Kind == TraceStep {
+			steps = append(steps, ev.Step)
+		}
+	}
+
+	assert.Equal([]string{"_attr_regex", "_type:i"}, steps)
+
+	id := rec.Filter("jobs.1.id")
+	last := id[len(id)-1]
+	assert.Equal(TraceValue, last.Kind)
+	assert.Equal("jobs.id", last.KeyPath)
+	assert.Equal(20, last.After)
+
+	for _, ev := range id {
+		if ev.Step == "_attr_regex" {
+			assert.Equal("Job 20", ev.Before)
+			assert.Equal("20", ev.After)
+		}
+	}
+}
+
+func TestTextTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+jobs:
+  _locator: data.jobs
+  _index: ~
+  title: title
+`
+	var buf bytes.Buffer
+
+	p := NewJSONParser([]byte(`{"data": {"jobs": [{"title": "A"}]}}`), []byte(yml))
+	DoParse(p, WithTracer(NewTextTracer(&buf)))
+
+	out := buf.String()
+	assert.True(strings.HasPrefix(out, "stub jobs\n"), out)
+	assert.Contains(out, `locator jobs: "data.jobs" -> 1 matches`)
+	assert.Contains(out, "\n  value jobs.0.title: \"A\"\n")
+}
+
+func withoutDuration(ev TraceEvent) TraceEvent {
+	ev.Duration = 0
+	return ev
+}
@@ -0,0 +1,299 @@
+package xparse
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+)
+
+// options of _url, check URLOptions for more info
+const (
+	_urlStripParams = "strip_params"
+	_urlSortQuery   = "sort_query"
+	_urlSchemes     = "schemes"
+
+	// _rawURL is the parser-wide default of _url
+	_rawURL = "__raw.url"
+)
+
+// parts of _url_part, check AttrURLPart for more info
+const (
+	_urlPartScheme   = "scheme"
+	_urlPartHost     = "host"
+	_urlPartPath     = "path"
+	_urlPartQuery    = "query"
+	_urlPartFragment = "fragment"
+)
+
+// _defaultTrackingParams are removed from the query of _type: url, glob patterns are supported
+var _defaultTrackingParams = []string{"utm_*", "gclid", "fbclid", "msclkid", "yclid", "mc_cid", "mc_eid", "_ga"}
+
+type urlOptions struct {
+	stripParams []string
+	sortQuery   bool
+	// schemes is empty if all schemes are allowed
+	schemes []string
+}
+
+// BindSourceURL sets the url where the page is fetched from, relative urls are resolved against it,
+// or against <base href> of the page if any
+func (p *Parser) BindSourceURL(u string) {
+	p.sourceURL = u
+}
+
+// SourceURL returns the url bound by BindSourceURL
+func (p *Parser) SourceURL() string {
+	return p.sourceURL
+}
+
+// pageBaseURL returns the url which relative urls of page are resolved against,
+// it's <base href> > source url > __raw.site_url
+func (p *Parser) pageBaseURL() string {
+	base := p.sourceURL
+	if base == "" {
+		base = p.config.String("__raw.site_url")
+	}
+
+	if p.baseHref == "" {
+		return base
+	}
+
+	if v := resolveURL(base, p.baseHref); v != nil {
+		return v.String()
+	}
+
+	return base
+}
+
+func (p *Parser) newURLOptions(cfg map[string]any) *urlOptions {
+	opts := &urlOptions{stripParams: _defaultTrackingParams, sortQuery: true}
+
+	apply := func(v any) {
+		switch v := v.(type) {
+		case nil:
+		case map[string]any:
+			if params, ok := v[_urlStripParams]; ok {
+				opts.stripParams = cast.ToStringSlice(params)
+			}
+
+			if b, ok := v[_urlSortQuery]; ok {
+				opts.sortQuery = cast.ToBool(b)
+			}
+
+			if schemes, ok := v[_urlSchemes]; ok {
+				opts.schemes = cast.ToStringSlice(schemes)
+			}
+		default:
+			panic(xpretty.Redf("%s must be a map, but got (%T: %v)", URLOptions, v, v))
+		}
+	}
+
+	if v, ok := p.config.GetValue(_rawURL); ok {
+		apply(v)
+	}
+
+	apply(cfg[URLOptions])
+
+	return opts
+}
+
+// toURL resolves raw against the page base url and normalizes it, "" is returned if its scheme is not allowed
+func (p *Parser) toURL(raw any, cfg map[string]any) any {
+	s, ok := raw.(string)
+	if !ok {
+		return raw
+	}
+
+	if s = strings.TrimSpace(s); s == "" {
+		return s
+	}
+
+	u := resolveURL(p.pageBaseURL(), s)
+	if u == nil {
+		return ""
+	}
+
+	opts := p.newURLOptions(cfg)
+
+	if len(opts.schemes) != 0 && !containsFold(opts.schemes, u.Scheme) {
+		return ""
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = opts.query(u.RawQuery)
+
+	return u.String()
+}
+
+// query removes tracking params of rawQuery, and sorts it by key if sortQuery
+func (o *urlOptions) query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var pairs []string
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(name); err == nil && o.isTracking(name) {
+			continue
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	if o.sortQuery {
+		sort.SliceStable(pairs, func(i, j int) bool {
+			ki, _, _ := strings.Cut(pairs[i], "=")
+			kj, _, _ := strings.Cut(pairs[j], "=")
+
+			return ki < kj
+		})
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func (o *urlOptions) isTracking(name string) bool {
+	for _, pattern := range o.stripParams {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveURL resolves ref against base, nil is returned if either is invalid
+func resolveURL(base, ref string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil
+	}
+
+	if base == "" || u.IsAbs() {
+		return u
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return nil
+	}
+
+	return b.ResolveReference(u)
+}
+
+func containsFold(arr []string, s string) bool {
+	for _, v := range arr {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refineByURLPart returns the part of url, check AttrURLPart for more info
+func (p *Parser) refineByURLPart(raw any, cfg map[string]any) (refined any) {
+	part, ok := cfg[AttrURLPart]
+	if !ok {
+		return raw
+	}
+
+	defer p.traceStep(AttrURLPart, raw, time.Now(), &refined)
+
+	rawStr, _ := raw.(string)
+
+	u := resolveURL(p.pageBaseURL(), rawStr)
+	if u == nil {
+		return ""
+	}
+
+	return urlPart(u, cast.ToString(part))
+}
+
+func urlPart(u *url.URL, part string) any {
+	switch {
+	case part == _urlPartScheme:
+		return u.Scheme
+	case part == _urlPartHost:
+		return u.Hostname()
+	case part == _urlPartPath:
+		return u.Path
+	case part == _urlPartQuery:
+		return u.RawQuery
+	case part == _urlPartFragment:
+		return u.Fragment
+	case strings.HasPrefix(part, _urlPartQuery+"."):
+		return u.Query().Get(strings.TrimPrefix(part, _urlPartQuery+"."))
+	case strings.HasPrefix(part, _urlPartPath+"["):
+		i, err := cast.ToIntE(strings.TrimSuffix(strings.TrimPrefix(part, _urlPartPath+"["), "]"))
+		if err != nil {
+			panic(xpretty.Redf("invalid %s: %s, the index of path must be int", AttrURLPart, part))
+		}
+
+		segments := pathSegments(u.Path)
+		if i < 0 {
+			i += len(segments)
+		}
+
+		if i < 0 || i >= len(segments) {
+			return ""
+		}
+
+		return segments[i]
+	case strings.Contains(part, "{"):
+		captures, matched := matchPathTemplate(part, u.Path)
+		if !matched {
+			return ""
+		}
+
+		return captures
+	default:
+		panic(xpretty.Redf("unknown %s: %s", AttrURLPart, part))
+	}
+}
+
+// matchPathTemplate matches path with tpl like /job/{id}/{slug}, each {name} captures one segment
+func matchPathTemplate(tpl, urlPath string) (map[string]any, bool) {
+	want, got := pathSegments(tpl), pathSegments(urlPath)
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	captures := make(map[string]any)
+
+	for i, seg := range want {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			captures[seg[1:len(seg)-1]] = got[i]
+			continue
+		}
+
+		if seg != got[i] {
+			return nil, false
+		}
+	}
+
+	return captures, true
+}
+
+func pathSegments(urlPath string) []string {
+	var segments []string
+
+	for _, seg := range strings.Split(urlPath, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+
+	return segments
+}
@@ -0,0 +1,125 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _urlHTML = `<html><head><base href="/jobs/"></head><body>
+<a class="job" href="detail?id=1&utm_source=x&b=2&a=1#top">Go</a>
+<a class="ext" href="HTTPS://Example.COM/Path?fbclid=abc">Ext</a>
+<a class="js" href="javascript:void(0)">JS</a>
+</body></html>`
+
+func TestURLType(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+job:
+  _locator: a.job
+  _attr: href
+  _type: url
+raw_order:
+  _locator: a.job
+  _attr: href
+  _type: url
+  _url:
+    strip_params: [utm_*, b]
+    sort_query: false
+ext:
+  _locator: a.ext
+  _attr: href
+  _type: url
+js:
+  _locator: a.js
+  _attr: href
+  _type: url
+  _url:
+    schemes: [http, https]
+`
+	p := NewHTMLParser([]byte(_urlHTML), []byte(yml))
+	DoParse(p, WithSourceURL("https://www.example.com/search?q=go"))
+
+	assert.Equal("https://www.example.com/jobs/detail?a=1&b=2&id=1#top", p.ParsedData["job"])
+	assert.Equal("https://www.example.com/jobs/detail?id=1&a=1#top", p.ParsedData["raw_order"])
+	assert.Equal("https://example.com/Path", p.ParsedData["ext"])
+	assert.Equal("", p.ParsedData["js"])
+}
+
+func TestURLBase(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  site_url: https://site.com
+  url:
+    sort_query: false
+job:
+  _locator: a.job
+  _attr: href
+  _type: url
+`
+	p := NewHTMLParser([]byte(`<a class="job" href="/j?b=1&a=2">Go</a>`), []byte(yml))
+	p.DoParse()
+	assert.Equal("https://site.com/j?b=1&a=2", p.ParsedData["job"])
+
+	// <base> is resolved against site_url too
+	p = NewHTMLParser([]byte(_urlHTML), []byte(yml))
+	p.DoParse()
+	assert.Equal("https://site.com/jobs/detail?id=1&b=2&a=1#top", p.ParsedData["job"])
+}
+
+func TestURLPart(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+__raw:
+  site_url: https://jobs.example.com
+job:
+  _locator: a
+  _attr: href
+  id:
+    _attr: href
+    _url_part: path[1]
+  jk:
+    _attr: href
+    _url_part: query.jk
+  host:
+    _attr: href
+    _url_part: host
+  fragment:
+    _attr: href
+    _url_part: fragment
+  last:
+    _attr: href
+    _url_part: path[-1]
+  parts:
+    _attr: href
+    _url_part: /job/{id}/{slug}
+  unmatched:
+    _attr: href
+    _url_part: /company/{id}
+`
+	p := NewHTMLParser([]byte(`<a href="/job/12345/go-dev?jk=abc&ref=x#apply">Go</a>`), []byte(yml))
+	p.DoParse()
+
+	assert.Equal(map[string]any{
+		"id":        "12345",
+		"jk":        "abc",
+		"host":      "jobs.example.com",
+		"fragment":  "apply",
+		"last":      "go-dev",
+		"parts":     map[string]any{"id": "12345", "slug": "go-dev"},
+		"unmatched": "",
+	}, p.ParsedData["job"])
+
+	jyml := `
+jk:
+  _locator: url
+  _url_part: query.jk
+`
+	jp := NewJSONParser([]byte(`{"url": "https://x.com/viewjob?jk=j1"}`), []byte(jyml))
+	jp.DoParse()
+	assert.Equal("j1", jp.ParsedData["jk"])
+}
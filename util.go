@@ -226,10 +226,20 @@ func GetMapKeys(all *[]string, data any, args ...string) {
 	var dat map[string]any
 	switch datType := data.(type) {
 	case []map[string]any:
+		if len(datType) == 0 {
+			*all = append(*all, prefix)
+			return
+		}
+
 		dat = datType[0]
 	case map[string]any:
 		dat = datType
 	case []any:
+		if len(datType) == 0 {
+			*all = append(*all, prefix)
+			return
+		}
+
 		switch d1 := datType[0].(type) {
 		case map[string]any:
 			dat = d1
@@ -0,0 +1,233 @@
+package xparse
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/coghost/xpretty"
+	"github.com/spf13/cast"
+	"github.com/tidwall/gjson"
+)
+
+// condition keys of _when, check When for more info
+const (
+	_whenExists    = "exists"
+	_whenNotExists = "not_exists"
+	_whenLocator   = "locator"
+	_whenAttr      = "attr"
+	_whenEquals    = "equals"
+	_whenContains  = "contains"
+	_whenMatches   = "matches"
+	_whenPreset    = "preset"
+	_whenAny       = "any"
+	_whenNot       = "not"
+)
+
+// whenNode is the node where _when is evaluated
+type whenNode interface {
+	exists(locator string) bool
+	// value returns text (or attr) of the first node of locator, the node itself is used if locator is empty
+	value(locator, attr string) string
+}
+
+type htmlWhenNode struct {
+	selection *goquery.Selection
+}
+
+func (n htmlWhenNode) exists(locator string) bool {
+	return findLocator(n.selection, locator).Length() != 0
+}
+
+func (n htmlWhenNode) value(locator, attr string) string {
+	sel := n.selection
+	if locator != "" {
+		sel = findLocator(sel, locator).First()
+	}
+
+	if attr == "" {
+		return strings.TrimSpace(sel.Text())
+	}
+
+	return sel.AttrOr(attr, "")
+}
+
+type jsonWhenNode struct {
+	result gjson.Result
+}
+
+func (n jsonWhenNode) exists(locator string) bool {
+	return n.result.Get(locator).Exists()
+}
+
+func (n jsonWhenNode) value(locator, _ string) string {
+	if locator == "" {
+		return n.result.String()
+	}
+
+	return n.result.Get(locator).String()
+}
+
+// whenAllowed returns true if cfg has no _when, or its _when matches
+func (p *Parser) whenAllowed(key string, cfg map[string]any, node whenNode) bool {
+	cond, ok := cfg[When]
+	if !ok {
+		return true
+	}
+
+	return p.matchWhen(key, cond, node)
+}
+
+// pickCase returns the first config of cases which is allowed by its _when
+func (p *Parser) pickCase(key string, cases []any, node whenNode) (map[string]any, bool) {
+	for _, c := range cases {
+		if _, ok := c.(map[string]any); !ok {
+			panic(xpretty.Redf("unknown type of (%v:%v), only support (1:string or 2:map[string]any)", key, cases))
+		}
+	}
+
+	for _, c := range cases {
+		cfg, _ := c.(map[string]any)
+
+		if p.whenAllowed(key, cfg, node) {
+			return cfg, true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *Parser) matchWhen(key string, cond any, node whenNode) bool {
+	switch cond := cond.(type) {
+	case bool:
+		return cond
+	case string:
+		return node.exists(cond)
+	case []any:
+		for _, c := range cond {
+			if !p.matchWhen(key, c, node) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]any:
+		return p.matchWhenMap(key, cond, node)
+	default:
+		panic(xpretty.Redf("_when of %s must be bool/string/list/map, but got (%T: %v)", key, cond, cond))
+	}
+}
+
+func (p *Parser) matchWhenMap(key string, cond map[string]any, node whenNode) bool {
+	locator := cast.ToString(cond[_whenLocator])
+	attr := cast.ToString(cond[_whenAttr])
+
+	for k, v := range cond {
+		var ok bool
+
+		switch k {
+		case _whenLocator, _whenAttr:
+			continue
+		case _whenExists:
+			ok = allLocators(v, node.exists)
+		case _whenNotExists:
+			ok = allLocators(v, func(s string) bool { return !node.exists(s) })
+		case _whenEquals:
+			ok = node.value(locator, attr) == cast.ToString(v)
+		case _whenContains:
+			ok = strings.Contains(node.value(locator, attr), cast.ToString(v))
+		case _whenMatches:
+			ok = p.compileRegex(When+"."+_whenMatches, key, cast.ToString(v)).MatchString(node.value(locator, attr))
+		case _whenPreset:
+			ok = p.matchPreset(key, v)
+		case _whenAny:
+			arr, _ := v.([]any)
+			for _, c := range arr {
+				if ok = p.matchWhen(key, c, node); ok {
+					break
+				}
+			}
+		case _whenNot:
+			ok = !p.matchWhen(key, v, node)
+		default:
+			panic(xpretty.Redf("unknown condition (%s) in _when of %s", k, key))
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Parser) matchPreset(key string, want any) bool {
+	dat, ok := want.(map[string]any)
+	if !ok {
+		panic(xpretty.Redf("_when.preset of %s must be a map, but got (%T: %v)", key, want, want))
+	}
+
+	preset := p.GetPresetData()
+
+	for k, v := range dat {
+		got, found := preset[k]
+		if !found || cast.ToString(got) != cast.ToString(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compileWhen compiles the patterns of matches in cond, check compileRegexes
+func (p *Parser) compileWhen(key string, cond any) {
+	switch cond := cond.(type) {
+	case []any:
+		for _, c := range cond {
+			p.compileWhen(key, c)
+		}
+	case map[string]any:
+		for k, v := range cond {
+			switch k {
+			case _whenMatches:
+				p.compileRegex(When+"."+_whenMatches, key, cast.ToString(v))
+			case _whenAny, _whenNot:
+				p.compileWhen(key, v)
+			}
+		}
+	}
+}
+
+// isStubConfig returns true if cfg is a map, or a list of config alternatives (maps),
+// which can be parsed at top level
+func isStubConfig(cfg any) bool {
+	switch cfg := cfg.(type) {
+	case map[string]any:
+		return true
+	case []any:
+		for _, c := range cfg {
+			if _, ok := c.(map[string]any); !ok {
+				return false
+			}
+		}
+
+		return len(cfg) != 0
+	default:
+		return false
+	}
+}
+
+// allLocators calls fn with locators, which is a string or a list of string
+func allLocators(locators any, fn func(string) bool) bool {
+	switch v := locators.(type) {
+	case []any:
+		for _, s := range v {
+			if !fn(cast.ToString(s)) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return fn(cast.ToString(v))
+	}
+}
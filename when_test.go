@@ -0,0 +1,103 @@
+package xparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLWhen(t *testing.T) {
+	assert := assert.New(t)
+
+	rawHTML := `<html><body>
+<div class="card"><span class="sponsored">Ad</span><h3>Sponsored Job</h3><a href="/ad/1">go</a></div>
+<div class="card"><h2>Organic Job</h2><a href="/jobs/2">go</a></div>
+</body></html>`
+
+	yml := `
+jobs:
+  _locator: div.card
+  _index: ~
+  title:
+    - _when: span.sponsored
+      _locator: h3
+    - _locator: h2
+  sponsored:
+    _when:
+      locator: a
+      attr: href
+      matches: ^/ad/
+    _locator: span.sponsored
+  organic:
+    _when:
+      not_exists: span.sponsored
+      preset: {country: CH}
+    _locator: a
+    _attr: href
+`
+	p := NewHTMLParser([]byte(rawHTML), []byte(yml))
+	p.BindPresetData(map[string]any{"country": "CH"})
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "Sponsored Job", "sponsored": "Ad", "country": "CH"},
+		{"title": "Organic Job", "organic": "/jobs/2", "country": "CH"},
+	}, p.ParsedData["jobs"])
+}
+
+func TestJSONWhen(t *testing.T) {
+	assert := assert.New(t)
+
+	rawJSON := `{"jobs": [
+  {"type": "ad", "ad": {"title": "A"}},
+  {"type": "organic", "title": "B", "salary": "10"}
+]}`
+
+	yml := `
+jobs:
+  _locator: jobs
+  _index: ~
+  title:
+    - _when: {locator: type, equals: ad}
+      _locator: ad.title
+    - _locator: title
+  salary:
+    _when:
+      any: [salary, pay]
+      not: {locator: type, contains: ad}
+    _locator: salary
+    _type: i
+`
+	p := NewJSONParser([]byte(rawJSON), []byte(yml))
+	p.DoParse()
+
+	assert.Equal([]map[string]any{
+		{"title": "A"},
+		{"title": "B", "salary": 10},
+	}, p.ParsedData["jobs"])
+
+	p = NewJSONParser([]byte(rawJSON), []byte("jobs:\n  _locator: jobs\n  _when: {unknown: 1}\n  title: title\n"))
+	assert.Panics(p.DoParse)
+}
+
+func TestWhenLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	// invalid matches is reported when config is loaded
+	assert.Panics(func() {
+		NewHTMLParser([]byte(`<p>a</p>`), []byte("title:\n  _when: {any: [{matches: \"(\"}]}\n  _locator: p\n"))
+	})
+
+	// a top-level list of strings is skipped as non-map, while a list of maps is config alternatives
+	yml := `
+tags: [a, b]
+title:
+  - _when: {locator: h1, matches: "^T"}
+    _locator: h1
+  - _locator: p
+`
+	p := NewHTMLParser([]byte(`<h1>News</h1><p>Text</p>`), []byte(yml))
+	assert.NotPanics(p.DoParse)
+
+	assert.Equal(map[string]any{"title": "Text"}, p.ParsedData)
+}
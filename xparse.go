@@ -3,13 +3,14 @@ package xparse
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"math"
 	"os"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/coghost/xdtm"
 	"github.com/coghost/xparse/plugin/js"
 	"github.com/coghost/xparse/plugin/py3"
 	"github.com/coghost/xpretty"
@@ -40,6 +41,8 @@ const (
 type Parser struct {
 	sourceData []byte
 	sourceYaml [][]byte
+	// resolvedYaml is the effective yaml of sourceYaml, check ResolveYaml
+	resolvedYaml []byte
 
 	config *config.Config
 
@@ -55,6 +58,10 @@ type Parser struct {
 
 	rank       int
 	rankOffset int
+	// rankBase is the rank of the first item, check BindRankBase
+	rankBase int
+	// page is the current page number, check BindPage
+	page int
 	// use the real order of page or not (which is same as _index:)
 	rankAsIndex bool
 
@@ -98,6 +105,40 @@ type Parser struct {
 	Refiners map[string]func(raw ...any) any
 
 	AttrToBeRefined []string
+
+	// stubHooks is a map of stub key and its hooks, check RefineStubsWithPreset for more info
+	stubHooks map[string][]StubHook
+
+	// outputPath is the path of current node in ParsedData, like ["jobs", "0", "title"]
+	outputPath []string
+	// keyPath is the path of current node in yaml config, like ["jobs", "title"]
+	keyPath []string
+
+	// fallbackStats counts the alternative used of each _fallback, check FallbackStats
+	fallbackStats map[string]map[int]int
+
+	// provenance is nil unless ToggleProvenance(true)
+	provenance *provenanceTracker
+	// tracer is nil unless BindTracer
+	tracer Tracer
+
+	// textMode is the default _text of leaves, check BindTextMode
+	textMode any
+
+	// sourceURL is where the page is fetched from, check BindSourceURL
+	sourceURL string
+	// baseHref is the href of <base> of html page
+	baseHref string
+
+	// regexes are compiled _attr_regex, _when.matches and _label, keyed by pattern
+	regexes map[string]*regexp.Regexp
+	// attrRegexes are parsed _attr_regex, keyed by the printed option
+	attrRegexes map[string]*attrRegex
+
+	// now is the reference time of relative dates, check BindNow
+	now time.Time
+	// timezone of dates, check BindTimezone
+	timezone *time.Location
 }
 
 func NewParser(raw []byte, ymlMap ...[]byte) *Parser {
@@ -108,6 +149,8 @@ func NewParser(raw []byte, ymlMap ...[]byte) *Parser {
 		ParsedData: make(map[string]any),
 		Refiners:   make(map[string]func(args ...any) any),
 
+		stubHooks: make(map[string][]StubHook),
+
 		rankAsIndex: false,
 	}
 }
@@ -122,10 +165,41 @@ func (p *Parser) Debug(key any, raw ...any) {
 	}
 }
 
+// LoadConfig loads yaml sources, which cannot have __raw.extends, use LoadConfigFS or ConfigStore for extends.
+//
+// Note: `key: ~` in a later source deletes the key of former sources, check ResolveYaml
 func (p *Parser) LoadConfig(ymlCfg ...[]byte) {
-	p.config = Yaml2Config(ymlCfg...)
+	p.LoadConfigFS(nil, ymlCfg...)
+}
+
+// LoadConfigFS is LoadConfig with __raw.extends resolved from root
+func (p *Parser) LoadConfigFS(root fs.FS, ymlCfg ...[]byte) {
+	resolved, err := ResolveYaml(root, ymlCfg...)
+	PanicIfErr(err)
+
+	if resolved == nil {
+		p.bindConfig(ymlCfg, resolved, Yaml2Config(ymlCfg...))
+	} else {
+		p.bindConfig(ymlCfg, resolved, Yaml2Config(resolved))
+	}
+}
+
+func (p *Parser) bindConfig(ymlCfg [][]byte, resolved []byte, cf *config.Config) {
+	p.sourceYaml = ymlCfg
+	p.resolvedYaml = resolved
+	p.config = cf
+
 	p.testKeys = p.config.Strings("__raw.test_keys")
 	p.verifyKeys = p.config.Strings("__raw.verify_keys")
+
+	if v, ok := p.config.GetValue(_rawText); ok {
+		p.textMode = v
+	}
+
+	p.rankBase = p.config.Int(_rawRankStart)
+	p.timezone = p.rawTimezone()
+
+	p.compileRegexes()
 }
 
 func (p *Parser) VerifyKeys() (arr []string) {
@@ -148,35 +222,6 @@ func (p *Parser) ExtraInfo() map[string]any {
 	return nil
 }
 
-func (p *Parser) AppendPresetData(data map[string]any) {
-	pd := p.GetPresetData()
-	for k, v := range pd {
-		_, b := data[k]
-		if !b {
-			data[k] = v
-		}
-	}
-
-	// try add parser unique id to data
-	_, found := data["site"]
-	if !found && p.PID != "" {
-		data["site"] = p.PID
-	}
-
-	// try add p.PID to external_id
-	v, found := data["external_id"]
-	if !found {
-		return
-	}
-
-	s, _ := v.(string)
-	pre := p.PID + "_"
-
-	if found && p.PID != "" && s != "" && !strings.HasPrefix(s, pre) {
-		data["external_id"] = p.PID + "_" + s
-	}
-}
-
 func (p *Parser) MustMandatoryFields(got, wanted []string) {
 	if len(got) == 0 || len(wanted) == 0 {
 		return
@@ -408,6 +453,12 @@ func (p *Parser) ToggleRankType(b bool) {
 }
 
 func (p *Parser) setRank(cfg map[string]any) {
+	p.setLocalRank(cfg)
+	p.rank = p.globalRank(cfg, p.rank)
+}
+
+// setLocalRank sets the rank in current stub
+func (p *Parser) setLocalRank(cfg map[string]any) {
 	idxGot := mustCfgIndex(cfg)
 
 	if idxGot == nil {
@@ -439,35 +490,32 @@ func (p *Parser) setRank(cfg map[string]any) {
 	}
 }
 
-func (p *Parser) convertToType(raw any, cfg map[string]any) any {
+func (p *Parser) convertToType(raw any, cfg map[string]any) (converted any) {
 	t, o := cfgType(cfg)
 	if o {
+		defer p.traceStep(fmt.Sprintf("%s:%v", Type, t), raw, time.Now(), &converted)
+
 		switch t {
 		case AttrTypeB:
 			return cast.ToBool(raw)
 		case AttrTypeI:
-			return cast.ToInt(math.Round(cast.ToFloat64(raw)))
+			return cast.ToInt(math.Round(p.toFloat(raw, cfg)))
 		case AttrTypeF:
-			return cast.ToFloat64(raw)
+			return p.toFloat(raw, cfg)
 		case AttrTypeT:
-			return p.formatDate(raw, false)
+			return p.formatDate(raw, false, cfg)
 		case AttrTypeT1:
-			return p.formatDate(raw, true)
+			return p.formatDate(raw, true, cfg)
+		case AttrTypeURL:
+			return p.toURL(raw, cfg)
+		case AttrTypeSalary:
+			return p.toSalary(raw, p.cfgLocale(cfg))
 		}
 	}
 
 	return raw
 }
 
-func (p *Parser) formatDate(raw any, bySearch bool) any {
-	rawStr, _ := raw.(string)
-	if v := xdtm.GetDateTimeStr(rawStr, xdtm.WithBySearch(bySearch)); v != "" {
-		return v
-	}
-
-	return raw
-}
-
 func (p *Parser) TrimSpace(txt string, cfg map[string]any) string {
 	st := cfg[Strip]
 	if st == false {
@@ -486,7 +534,7 @@ func (p *Parser) stripChars(key string, raw any, cfg map[string]any) any {
 	}
 }
 
-func (p *Parser) stripStrings(_ string, raw any, cfg map[string]any) any {
+func (p *Parser) stripStrings(_ string, raw any, cfg map[string]any) (stripped any) {
 	rawStr, _ := raw.(string)
 
 	st := cfg[Strip]
@@ -494,6 +542,8 @@ func (p *Parser) stripStrings(_ string, raw any, cfg map[string]any) any {
 		return strings.TrimSpace(rawStr)
 	}
 
+	defer p.traceStep(Strip, raw, time.Now(), &stripped)
+
 	switch stripType := st.(type) {
 	case string:
 		return strings.ReplaceAll(rawStr, stripType, "")
@@ -570,28 +620,14 @@ func (p *Parser) loadPreDefined(mtdName string) (func(raw ...any) any, bool) {
 	}
 }
 
-func (p *Parser) refineByRe(raw any, cfg map[string]any) any {
-	rgx, ok := cfg[AttrRegex]
-	if !ok {
-		return raw
-	}
-
-	regex, err := regexp.Compile(rgx.(string))
-	if err != nil {
-		log.Error().Err(err).Interface("regex", rgx).Msg("cannot compile regex")
-	}
-
-	rawStr, _ := raw.(string)
-
-	return regex.FindString(rawStr)
-}
-
-func (p *Parser) refineByPython(raw any, cfg map[string]any) any {
+func (p *Parser) refineByPython(raw any, cfg map[string]any) (refined any) {
 	code, ok := cfg[AttrPython]
 	if !ok {
 		return raw
 	}
 
+	defer p.traceStep(AttrPython, raw, time.Now(), &refined)
+
 	codeStr, _ := code.(string)
 	rawStr, _ := raw.(string)
 
@@ -603,12 +639,14 @@ func (p *Parser) refineByPython(raw any, cfg map[string]any) any {
 	return resp.RefinedString
 }
 
-func (p *Parser) refineByJS(raw any, cfg map[string]any) any {
+func (p *Parser) refineByJS(raw any, cfg map[string]any) (refined any) {
 	code, ok := cfg[AttrJS]
 	if !ok {
 		return raw
 	}
 
+	defer p.traceStep(AttrJS, raw, time.Now(), &refined)
+
 	codeStr, _ := code.(string)
 	rawStr, _ := raw.(string)
 
@@ -620,15 +658,16 @@ func (p *Parser) refineByJS(raw any, cfg map[string]any) any {
 	return resp.RefinedString
 }
 
-func (p *Parser) advancedPostRefineAttr(raw any, cfg map[string]any) any {
-	raw = p.refineByRe(raw, cfg)
+func (p *Parser) advancedPostRefineAttr(key string, raw any, cfg map[string]any) any {
+	raw = p.refineByURLPart(raw, cfg)
+	raw = p.refineByRe(key, raw, cfg)
 	raw = p.refineByPython(raw, cfg)
 	raw = p.refineByJS(raw, cfg)
 
 	return raw
 }
 
-func (p *Parser) refineAttr(key string, raw any, cfg map[string]any, selection any) any {
+func (p *Parser) refineAttr(key string, raw any, cfg map[string]any, selection any) (refined any) {
 	attr := cfg[Attr]
 
 	refine := mustCfgAttrRefine(cfg)
@@ -637,6 +676,7 @@ func (p *Parser) refineAttr(key string, raw any, cfg map[string]any, selection a
 	}
 
 	snakeCaseName := p.convertAttrRefineToSnakeCaseName(key, refine, attr)
+	defer p.traceStep(snakeCaseName, raw, time.Now(), &refined)
 
 	// refiners from parser-defined is prior than pre-defined
 	injectFn, b := p.getRefinerFn(snakeCaseName)
@@ -34,7 +34,7 @@ func (p *Parser) EnrichURL(raw ...any) any {
 }
 
 func (p *Parser) EnrichUrl(raw ...any) any { //nolint
-	domain := p.config.String("__raw.site_url")
+	domain := p.pageBaseURL()
 	uri := EnrichURL(domain, raw[0])
 
 	return uri
@@ -220,28 +220,3 @@ func (p *Parser) RefineEncodedJson(raw ...any) any { //nolint
 
 	return data
 }
-
-func (p *Parser) RefineJobsWithPreset() {
-	p.refineJobs()
-	p.refineJob()
-}
-
-func (p *Parser) refineJobs() {
-	jobs, ok := p.GetParsedData("jobs").([]map[string]any)
-	if !ok {
-		return
-	}
-
-	for _, job := range jobs {
-		p.AppendPresetData(job)
-	}
-}
-
-func (p *Parser) refineJob() {
-	job, ok := p.GetParsedData("job").(map[string]any)
-	if !ok {
-		return
-	}
-
-	p.AppendPresetData(job)
-}